@@ -0,0 +1,125 @@
+package trie
+
+import "sort"
+
+// ChangeKind says how a key changed in a transaction.
+type ChangeKind uint8
+
+const (
+	Inserted ChangeKind = iota + 1
+	Updated
+	Deleted
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Inserted:
+		return "Inserted"
+	case Updated:
+		return "Updated"
+	case Deleted:
+		return "Deleted"
+	}
+	return "ChangeKind(?)"
+}
+
+// Change describes a key whose value was changed by a transaction, or that
+// differs between the tries given to Diff.
+type Change[V any] struct {
+	Key  []byte
+	Kind ChangeKind
+	Old  V // value before the change, or the zero value if inserted
+	New  V // value after the change, or the zero value if deleted
+}
+
+// change is one entry of a Txn's change log.
+type change[V any] struct {
+	key            string
+	old, new       V
+	hadOld, hasNew bool
+}
+
+// TrackChanges makes the transaction record every key it changes, to be
+// returned by Changes. It must be called before the changes are made.
+func (t *Txn[V]) TrackChanges() {
+	t.checkOpen()
+	if t.changes == nil {
+		t.changes = make([]change[V], 0, 8)
+	}
+}
+
+// Changes returns the keys changed by the transaction so far, in key order.
+// A key changed more than once is reported once, with its original and final
+// values; keys that ended up with their original value are left out. It
+// returns nil unless TrackChanges was called.
+func (t *Txn[V]) Changes() []Change[V] {
+	if len(t.changes) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(t.changes))
+	merged := make([]change[V], 0, len(t.changes))
+	for _, c := range t.changes {
+		if i, ok := index[c.key]; ok {
+			merged[i].new, merged[i].hasNew = c.new, c.hasNew
+			continue
+		}
+		index[c.key] = len(merged)
+		merged = append(merged, c)
+	}
+
+	var cs []Change[V]
+	for _, c := range merged {
+		var kind ChangeKind
+		switch {
+		case c.hadOld && c.hasNew:
+			if t.equal(c.old, c.new) {
+				continue
+			}
+			kind = Updated
+		case c.hasNew:
+			kind = Inserted
+		case c.hadOld:
+			kind = Deleted
+		default:
+			continue
+		}
+		cs = append(cs, Change[V]{Key: []byte(c.key), Kind: kind, Old: c.old, New: c.new})
+	}
+	sort.Slice(cs, func(i, j int) bool { return string(cs[i].Key) < string(cs[j].Key) })
+	return cs
+}
+
+// tracking reports whether changes need to be recorded.
+func (t *Txn[V]) tracking() bool {
+	return t.changes != nil
+}
+
+// record logs a change to k, if it changes anything. hadOld and hasNew say
+// whether k held a value before and after the change.
+func (t *Txn[V]) record(k string, old V, hadOld bool, new V, hasNew bool) {
+	if hadOld == hasNew && (!hadOld || t.equal(old, new)) {
+		return
+	}
+	t.changes = append(t.changes, change[V]{key: k, old: old, new: new, hadOld: hadOld, hasNew: hasNew})
+}
+
+// recordNodes logs a change reported by diffNodes.
+func (t *Txn[V]) recordNodes(k []byte, a, b *Node[V]) {
+	var old, new V
+	if a != nil {
+		old = a.value
+	}
+	if b != nil {
+		new = b.value
+	}
+	t.record(string(k), old, a != nil, new, b != nil)
+}
+
+// recordMerge logs the changes merging n into the root will make.
+func (t *Txn[V]) recordMerge(n *Node[V]) {
+	diffNodes(t.root, n, t.eq, func(k []byte, a, b *Node[V]) {
+		if b != nil {
+			t.recordNodes(k, a, b)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTxnChanges(t *testing.T) {
+	tx := &Txn[int]{root: buildIterTrie(iterKeys)}
+	tx.TrackChanges()
+
+	tx.PutString("abc", 30)
+	tx.PutString("abc", 31)
+	tx.DeleteString("ba")
+	tx.Put([]byte("bz"), 40)
+	tx.PutString("foo", 10) // same value
+	tx.PutString("new", 1)
+	tx.DeleteString("new") // inserted, then deleted
+	tx.Delete([]byte("nope"))
+	tx.PutString("c", 0) // zero is a value like any other
+
+	other := (*Node[int])(nil).PutString("a", 2).PutString("zz", 2) // "a" is unchanged
+	tx.Merge(other.PutString("foo bar", 99))
+	tx.Commit()
+
+	want := "[{abc Updated 4 31} {ba Deleted 7 0} {bz Inserted 0 40} {c Updated 9 0} {foo bar Updated 11 99} {zz Inserted 0 2}]"
+
+	var got []string
+	for _, c := range tx.Changes() {
+		got = append(got, fmt.Sprintf("{%s %v %d %d}", c.Key, c.Kind, c.Old, c.New))
+	}
+	if fmt.Sprint(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestTxnChangesDisabled(t *testing.T) {
+	tx := new(Txn[int])
+	tx.PutString("foo", 1)
+
+	if cs := tx.Changes(); cs != nil {
+		t.Errorf("expected no changes without tracking, got %v", cs)
+	}
+}
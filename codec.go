@@ -0,0 +1,160 @@
+package trie
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Codec converts values to and from bytes when a trie is serialized.
+type Codec[V any] interface {
+	// AppendValue appends the encoding of v to b.
+	AppendValue(b []byte, v V) ([]byte, error)
+
+	// DecodeValue decodes a value from b. It must not retain b.
+	DecodeValue(b []byte) (V, error)
+}
+
+// DefaultCodec returns the codec used when none is given. Byte slices and
+// strings are stored as is, and types implementing encoding.BinaryMarshaler
+// (with a pointer implementing encoding.BinaryUnmarshaler) use those methods.
+// Other types are stored by kind: booleans as one byte, signed integers as
+// zig-zag varints, unsigned integers as uvarints and floats as their IEEE 754
+// bits in little endian order. The encoding depends only on the value, not on
+// the name of its type or where it is stored, so it is fit for hashing.
+//
+// Any other type has no default codec; the codec returned fails on every call,
+// so serializing or hashing a trie with values of such a type needs a Codec.
+func DefaultCodec[V any]() Codec[V] {
+	var zero V
+	switch any(zero).(type) {
+	case []byte:
+		return bytesCodec[V]{}
+	case string:
+		return stringCodec[V]{}
+	case encoding.BinaryMarshaler:
+		if _, ok := any(&zero).(encoding.BinaryUnmarshaler); ok {
+			return binaryCodec[V]{}
+		}
+	}
+	switch t := reflect.TypeOf(&zero).Elem(); t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return kindCodec[V]{}
+	default:
+		return noCodec[V]{t}
+	}
+}
+
+type bytesCodec[V any] struct{}
+
+func (bytesCodec[V]) AppendValue(b []byte, v V) ([]byte, error) {
+	return append(b, any(v).([]byte)...), nil
+}
+
+func (bytesCodec[V]) DecodeValue(b []byte) (V, error) {
+	return any(bytes.Clone(b)).(V), nil
+}
+
+type stringCodec[V any] struct{}
+
+func (stringCodec[V]) AppendValue(b []byte, v V) ([]byte, error) {
+	return append(b, any(v).(string)...), nil
+}
+
+func (stringCodec[V]) DecodeValue(b []byte) (V, error) {
+	return any(string(b)).(V), nil
+}
+
+type binaryCodec[V any] struct{}
+
+func (binaryCodec[V]) AppendValue(b []byte, v V) ([]byte, error) {
+	data, err := any(v).(encoding.BinaryMarshaler).MarshalBinary()
+	return append(b, data...), err
+}
+
+func (binaryCodec[V]) DecodeValue(b []byte) (v V, err error) {
+	err = any(&v).(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+	return
+}
+
+// kindCodec stores values of named basic types by their kind.
+type kindCodec[V any] struct{}
+
+func (kindCodec[V]) AppendValue(b []byte, v V) ([]byte, error) {
+	switch rv := reflect.ValueOf(&v).Elem(); rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.String:
+		return append(b, rv.String()...), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(b, rv.Int()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(rv.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(rv.Float())), nil
+	default:
+		return binary.AppendUvarint(b, rv.Uint()), nil
+	}
+}
+
+func (kindCodec[V]) DecodeValue(b []byte) (v V, err error) {
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Bool:
+		if len(b) != 1 || b[0] > 1 {
+			return v, ErrInvalidFormat
+		}
+		rv.SetBool(b[0] == 1)
+	case reflect.String:
+		rv.SetString(string(b))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(b)
+		if n != len(b) || rv.OverflowInt(x) {
+			return v, ErrInvalidFormat
+		}
+		rv.SetInt(x)
+	case reflect.Float32:
+		if len(b) != 4 {
+			return v, ErrInvalidFormat
+		}
+		rv.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+	case reflect.Float64:
+		if len(b) != 8 {
+			return v, ErrInvalidFormat
+		}
+		rv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	default:
+		x, n := binary.Uvarint(b)
+		if n != len(b) || rv.OverflowUint(x) {
+			return v, ErrInvalidFormat
+		}
+		rv.SetUint(x)
+	}
+	return v, nil
+}
+
+// noCodec is the default codec for types without a default encoding.
+type noCodec[V any] struct {
+	t reflect.Type
+}
+
+func (c noCodec[V]) AppendValue(b []byte, v V) ([]byte, error) {
+	return b, c.err()
+}
+
+func (c noCodec[V]) DecodeValue(b []byte) (v V, err error) {
+	return v, c.err()
+}
+
+func (c noCodec[V]) err() error {
+	return fmt.Errorf("trie: no default codec for %v values; pass a Codec", c.t)
+}
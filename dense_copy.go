@@ -1,35 +1,37 @@
 package trie
 
-type copyTxn struct {
-	nodes []Node
-	edges []*Node
+type copyTxn[V any] struct {
+	nodes []Node[V]
+	edges []*Node[V]
 }
 
-func (root *Node) DenseCopy() *Node {
+func (root *Node[V]) DenseCopy() *Node[V] {
 	var n int
-	root.Walk(func(node *Node) bool {
+	root.Walk(func(node *Node[V]) bool {
 		n += 1
 		return true
 	})
-	t := &copyTxn{
-		nodes: make([]Node, n),
-		edges: make([]*Node, n-1),
+	t := &copyTxn[V]{
+		nodes: make([]Node[V], n),
+		edges: make([]*Node[V], n-1),
 	}
 	return t.copyNode(root)
 }
 
-func (t *copyTxn) copyNode(n *Node) (cp *Node) {
+func (t *copyTxn[V]) copyNode(n *Node[V]) (cp *Node[V]) {
 	if i := len(t.nodes) - 1; i >= 0 {
 		t.nodes, cp = t.nodes[:i:i], &t.nodes[i]
 		cp.key = n.key
 		cp.value = n.value
+		cp.present = n.present
 		cp.edges = t.copyEdges(n.edges)
+		cp.size = n.size
 		return
 	}
 	panic("node not preallocated")
 }
 
-func (t *copyTxn) copyEdges(es edges) (cp edges) {
+func (t *copyTxn[V]) copyEdges(es edges[V]) (cp edges[V]) {
 	n := len(es)
 	i := len(t.edges) - n
 	if i >= 0 {
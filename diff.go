@@ -0,0 +1,128 @@
+package trie
+
+// Diff calls fn for every key whose value differs between old and new, in key
+// order. The Kind of the Change tells a key missing from one side apart from
+// one holding the zero value.
+// Subtrees shared by both tries are skipped, so the cost is proportional to
+// the size of the change rather than the size of the tries.
+func Diff[V any](old, new *Node[V], fn func(Change[V])) {
+	DiffFunc(old, new, nil, fn)
+}
+
+// DiffFunc is like Diff, but compares values with eq. A nil eq falls back to
+// Equaler, then reflect.DeepEqual.
+func DiffFunc[V any](old, new *Node[V], eq Comparator[V], fn func(Change[V])) {
+	diffNodes(old, new, eq, func(k []byte, a, b *Node[V]) {
+		c := Change[V]{Key: k}
+		switch {
+		case a == nil:
+			c.Kind, c.New = Inserted, b.value
+		case b == nil:
+			c.Kind, c.Old = Deleted, a.value
+		default:
+			c.Kind, c.Old, c.New = Updated, a.value, b.value
+		}
+		fn(c)
+	})
+}
+
+// diffNodes is like DiffFunc, but passes fn the nodes holding the old and new
+// values, either of which is nil if the key has no value on that side.
+func diffNodes[V any](old, new *Node[V], eq Comparator[V], fn func(key []byte, a, b *Node[V])) {
+	d := &differ[V]{eq: eq, fn: fn}
+	d.nodes(0, old, new)
+}
+
+type differ[V any] struct {
+	eq Comparator[V]
+	fn func(key []byte, a, b *Node[V])
+}
+
+func (d *differ[V]) nodes(depth int, a, b *Node[V]) {
+	switch {
+	case a == b:
+		return
+	case a == nil:
+		d.added(b)
+		return
+	case b == nil:
+		d.removed(a)
+		return
+	}
+
+	n, short := a.key.commonBytesLen(b.key, depth)
+	switch {
+	case !short && n == len(b.key): // same key
+		d.values(a, b)
+		d.edges(n, a.edges, b.edges)
+	case !short: // a is a prefix of b
+		d.values(a, nil)
+		d.edges(n, a.edges, edges[V]{b})
+	case n == len(b.key): // b is a prefix of a
+		d.values(nil, b)
+		d.edges(n, edges[V]{a}, b.edges)
+	case a.key[n] < b.key[n]:
+		d.removed(a)
+		d.added(b)
+	default:
+		d.added(b)
+		d.removed(a)
+	}
+}
+
+// edges diffs two sets of edges by walking them in lockstep.
+func (d *differ[V]) edges(depth int, a, b edges[V]) {
+	for len(a) > 0 && len(b) > 0 {
+		switch x, y := a[0].key[depth], b[0].key[depth]; {
+		case x < y:
+			d.removed(a[0])
+			a = a[1:]
+		case x > y:
+			d.added(b[0])
+			b = b[1:]
+		default:
+			d.nodes(depth+1, a[0], b[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	for _, n := range a {
+		d.removed(n)
+	}
+	for _, n := range b {
+		d.added(n)
+	}
+}
+
+// values reports the value of a node present on one or both sides.
+func (d *differ[V]) values(a, b *Node[V]) {
+	if a != nil && !a.hasValue() {
+		a = nil
+	}
+	if b != nil && !b.hasValue() {
+		b = nil
+	}
+	switch {
+	case a != nil && b != nil:
+		if !d.eq.equal(a.value, b.value) {
+			d.fn(a.key, a, b)
+		}
+	case a != nil:
+		d.fn(a.key, a, nil)
+	case b != nil:
+		d.fn(b.key, nil, b)
+	}
+}
+
+func (d *differ[V]) added(n *Node[V]) {
+	n.Walk(func(n *Node[V]) bool {
+		d.fn(n.key, nil, n)
+		return true
+	})
+}
+
+func (d *differ[V]) removed(n *Node[V]) {
+	n.Walk(func(n *Node[V]) bool {
+		d.fn(n.key, n, nil)
+		return true
+	})
+}
@@ -0,0 +1,108 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+)
+
+type diffEntry struct {
+	Key           string
+	Before, After int
+}
+
+func collectDiff(a, b *Node[int]) []diffEntry {
+	var got []diffEntry
+	Diff(a, b, func(c Change[int]) {
+		got = append(got, diffEntry{string(c.Key), c.Old, c.New})
+	})
+	return got
+}
+
+func TestDiff(t *testing.T) {
+	a := buildIterTrie(iterKeys)
+
+	tx := &Txn[int]{root: a}
+	tx.PutString("abc", 30)
+	tx.DeleteString("ba")
+	tx.PutString("bz", 40)
+	tx.PutString("foo b", 50)
+	tx.DeleteString("food")
+	tx.PutString("foo", 10) // unchanged
+	b := tx.Commit()
+
+	want := []diffEntry{
+		{"abc", 4, 30},
+		{"ba", 7, 0},
+		{"bz", 0, 40},
+		{"foo b", 0, 50},
+		{"food", 13, 0},
+	}
+	if got := collectDiff(a, b); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for i := range want {
+		want[i].Before, want[i].After = want[i].After, want[i].Before
+	}
+	if got := collectDiff(b, a); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDiffLayouts(t *testing.T) {
+	table := []struct {
+		A, B []string
+		Want string
+	}{
+		{nil, []string{"a", "b"}, "[{a 0 1} {b 0 2}]"},
+		{[]string{"a", "b"}, nil, "[{a 1 0} {b 2 0}]"},
+		{[]string{"foo"}, []string{"foobar"}, "[{foo 1 0} {foobar 0 1}]"},
+		{[]string{"foobar"}, []string{"foo"}, "[{foo 0 1} {foobar 1 0}]"},
+		{[]string{"fob"}, []string{"foa"}, "[{foa 0 1} {fob 1 0}]"},
+		{[]string{"foo", "foobar"}, []string{"foobar", "foo"}, "[{foo 1 2} {foobar 2 1}]"},
+		{[]string{"foo", "foobar"}, []string{"foo", "foobar"}, "[]"},
+	}
+	for _, x := range table {
+		a, b := buildIterTrie(x.A), buildIterTrie(x.B)
+		if got := fmt.Sprint(collectDiff(a, b)); got != x.Want {
+			t.Errorf("%q to %q: expected %s, got %s", x.A, x.B, x.Want, got)
+		}
+	}
+}
+
+func TestDiffSkipsShared(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%04d", i)
+	}
+	a := buildIterTrie(keys)
+	b := a.PutString("key0500", -1)
+
+	var compared, changed int
+	eq := func(x, y int) bool {
+		compared++
+		return x == y
+	}
+	DiffFunc(a, b, eq, func(Change[int]) { changed++ })
+
+	if changed != 1 {
+		t.Errorf("expected 1 change, got %d", changed)
+	}
+	if compared != 1 {
+		t.Errorf("expected only the changed value to be compared, got %d comparisons", compared)
+	}
+}
+
+func TestDiffZeroValues(t *testing.T) {
+	a := (*Node[int])(nil).PutString("gone", 0).PutString("same", 1).PutString("set", 2)
+	b := (*Node[int])(nil).PutString("new", 0).PutString("same", 1).PutString("set", 0)
+
+	var got []string
+	Diff(a, b, func(c Change[int]) {
+		got = append(got, fmt.Sprintf("%s %v %d %d", c.Key, c.Kind, c.Old, c.New))
+	})
+	want := []string{"gone Deleted 0 0", "new Inserted 0 0", "set Updated 2 0"}
+	if !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
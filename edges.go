@@ -2,11 +2,11 @@ package trie
 
 import "sort"
 
-type edges []*Node
+type edges[V any] []*Node[V]
 
 // add inserts the given node into into the target, returning the new edges and
 // true if a new slice was allocated.
-func (es edges) add(t *Txn, depth int, node *Node, reverse bool) (edges, bool) {
+func (es edges[V]) add(t *Txn[V], depth int, node *Node[V], reverse bool) (edges[V], bool) {
 	i, old := es.get(node.key[depth], depth)
 	if old != nil {
 		if node, _ := mergeNodes(t, depth+1, old, node, reverse); node != old {
@@ -19,8 +19,8 @@ func (es edges) add(t *Txn, depth int, node *Node, reverse bool) (edges, bool) {
 
 // cut removes n nodes starting at i from the target, returning true if a new
 // slice was allocated (currently always true).
-func (es edges) cut(t *Txn, i, n int) (edges, bool) {
-	cp := make(edges, len(es)-n)
+func (es edges[V]) cut(t *Txn[V], i, n int) (edges[V], bool) {
+	cp := make(edges[V], len(es)-n)
 	copy(cp, es[:i])
 	copy(cp[i:], es[i+n:])
 	if debugEnabled && !cp.valid() {
@@ -29,7 +29,7 @@ func (es edges) cut(t *Txn, i, n int) (edges, bool) {
 	return cp, true
 }
 
-func (es edges) delete(t *Txn, depth int, k []byte) (edges, bool) {
+func (es edges[V]) delete(t *Txn[V], depth int, k []byte) (edges[V], bool) {
 	i, old := es.get(k[depth], depth)
 	if old != nil {
 		if n := old.delete(t, depth+1, k); n == nil {
@@ -41,7 +41,7 @@ func (es edges) delete(t *Txn, depth int, k []byte) (edges, bool) {
 	return es, false
 }
 
-func (es edges) deleteString(t *Txn, depth int, k string) (edges, bool) {
+func (es edges[V]) deleteString(t *Txn[V], depth int, k string) (edges[V], bool) {
 	i, old := es.get(k[depth], depth)
 	if old != nil {
 		if n := old.deleteString(t, depth+1, k); n == nil {
@@ -53,7 +53,7 @@ func (es edges) deleteString(t *Txn, depth int, k string) (edges, bool) {
 	return es, false
 }
 
-func (es edges) get(label byte, depth int) (int, *Node) {
+func (es edges[V]) get(label byte, depth int) (int, *Node[V]) {
 	i, n := es.search(label, depth)
 	if i != n {
 		if nd := es[i]; nd.key[depth] == label {
@@ -65,18 +65,18 @@ func (es edges) get(label byte, depth int) (int, *Node) {
 
 // insert replaces skip nodes with node, starting at i. It returns the new
 // edges and true if a new slice was allocated (currently always true).
-func (es edges) insert(t *Txn, i, skip int, node *Node) (edges, bool) {
+func (es edges[V]) insert(t *Txn[V], i, skip int, node *Node[V]) (edges[V], bool) {
 	if debugEnabled && len(node.key) == 0 {
 		panic("key too short")
 	}
-	cp := make(edges, len(es)+1-skip)
+	cp := make(edges[V], len(es)+1-skip)
 	copy(cp, es[:i])
 	cp[i] = node
 	copy(cp[i+1:], es[i+skip:])
 	return cp, true
 }
 
-func (a edges) put(t *Txn, depth int, k []byte, v interface{}, b edges) (edges, bool) {
+func (a edges[V]) put(t *Txn[V], depth int, k []byte, v V, b edges[V]) (edges[V], bool) {
 	i, old := a.get(k[depth], depth)
 	if old != nil {
 		if n := old.put(t, depth+1, k, v, b); n != old {
@@ -84,10 +84,10 @@ func (a edges) put(t *Txn, depth int, k []byte, v interface{}, b edges) (edges,
 		}
 		return a, false
 	}
-	return a.insert(t, i, 0, t.newNode(k, v, b))
+	return a.insert(t, i, 0, t.newNode(k, v, true, b))
 }
 
-func (a edges) putString(t *Txn, depth int, k string, v interface{}, b edges) (edges, bool) {
+func (a edges[V]) putString(t *Txn[V], depth int, k string, v V, b edges[V]) (edges[V], bool) {
 	i, old := a.get(k[depth], depth)
 	if old != nil {
 		if n := old.putString(t, depth+1, k, v, b); n != old {
@@ -95,10 +95,10 @@ func (a edges) putString(t *Txn, depth int, k string, v interface{}, b edges) (e
 		}
 		return a, false
 	}
-	return a.insert(t, i, 0, t.newNode(Key(k), v, b))
+	return a.insert(t, i, 0, t.newNode(Key(k), v, true, b))
 }
 
-func (es edges) search(label byte, depth int) (i, n int) {
+func (es edges[V]) search(label byte, depth int) (i, n int) {
 	n = len(es)
 	i = sort.Search(n, func(idx int) bool { return es[idx].key[depth] >= label })
 	return
@@ -4,14 +4,14 @@ import "testing"
 
 func TestEdgeAdd(t *testing.T) {
 	var (
-		n *Node
-		o = &Node{key: Key("foods"), value: 1}
-		p = &Node{key: Key("foodz"), value: 2}
+		n *AnyNode
+		o = node("foods", 1, nil)
+		p = node("foodz", 2, nil)
 	)
 
-	n = &Node{key: Key("foodie"), value: 3}
+	n = node("foodie", 3, nil)
 
-	if res, modified := (edges{o, p}).add(new(Txn), 4, n, false); !modified {
+	if res, modified := (anyEdges{o, p}).add(new(AnyTxn), 4, n, false); !modified {
 		t.Errorf("expected [%q, %q] to be modified when adding %q", o.key, p.key, n.key)
 	} else {
 		assertExactNode(t, n, res[0])
@@ -19,13 +19,13 @@ func TestEdgeAdd(t *testing.T) {
 		assertExactNode(t, p, res[2])
 	}
 
-	if _, modified := (edges{o, p}).add(new(Txn), 4, p, false); modified {
+	if _, modified := (anyEdges{o, p}).add(new(AnyTxn), 4, p, false); modified {
 		t.Errorf("expected [%q, %q] to NOT be modified when adding %q", o.key, p.key, p.key)
 	}
 
-	n = &Node{key: Key("foodz"), value: 4}
+	n = node("foodz", 4, nil)
 
-	if res, modified := (edges{o, p}).add(new(Txn), 4, n, false); !modified {
+	if res, modified := (anyEdges{o, p}).add(new(AnyTxn), 4, n, false); !modified {
 		t.Errorf("expected [%q, %q] to be modified when adding %q with new value", o.key, p.key, n.key)
 	} else if len(res) != 2 {
 		t.Errorf("expected length to stay 2")
@@ -0,0 +1,288 @@
+package trie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The serialized form of a trie is a header followed by the nodes in key
+// order. The header is the magic string "trie", a version byte and a byte
+// that is 1 if a root follows. Each node is written as:
+//
+//	uvarint  length of the key suffix (the key minus the parent's key)
+//	bytes    key suffix
+//	byte     flags; flagValue is set if the node holds a value
+//	uvarint  length of the encoded value (only if flagValue is set)
+//	bytes    encoded value (only if flagValue is set)
+//	uvarint  number of edges
+//
+// followed by each of its edges, recursively. Keys may be at most maxKeyLen
+// bytes long, and every node but the root has a value or at least two edges.
+const (
+	encodeMagic   = "trie"
+	encodeVersion = 1
+
+	flagValue = 1 << 0
+)
+
+// maxKeyLen bounds the keys of a serialized trie. Every node holds its whole
+// key, so without a bound a short input of nodes with one byte suffixes could
+// make ReadNode allocate memory quadratic in its length.
+const maxKeyLen = 16 << 10
+
+// ErrInvalidFormat is returned when reading data that is not a serialized
+// trie, or is truncated or corrupt.
+var ErrInvalidFormat = errors.New("trie: invalid serialized trie")
+
+// WriteTo writes n to w using DefaultCodec for its values. It implements
+// io.WriterTo.
+func (n *Node[V]) WriteTo(w io.Writer) (int64, error) {
+	return n.WriteToCodec(w, DefaultCodec[V]())
+}
+
+// WriteToCodec is like WriteTo, but encodes values with c.
+func (n *Node[V]) WriteToCodec(w io.Writer, c Codec[V]) (int64, error) {
+	e := &encoder[V]{w: bufio.NewWriter(w), codec: c}
+	e.buf = append(e.buf, encodeMagic...)
+	e.buf = append(e.buf, encodeVersion)
+	if n == nil {
+		e.buf = append(e.buf, 0)
+		e.flush()
+	} else {
+		e.buf = append(e.buf, 1)
+		e.node(n, 0)
+	}
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.n, e.err
+}
+
+// ReadNode reads a trie written by WriteTo, using DefaultCodec for its
+// values. The nodes are rebuilt with the same layout they were written with.
+// If r implements io.ByteReader, ReadNode stops at the end of the trie, so it
+// can be read from the middle of a larger stream; otherwise it buffers r and
+// may read past the end.
+func ReadNode[V any](r io.Reader) (*Node[V], error) {
+	return ReadNodeCodec(r, DefaultCodec[V]())
+}
+
+// ReadNodeCodec is like ReadNode, but decodes values with c.
+func ReadNodeCodec[V any](r io.Reader, c Codec[V]) (*Node[V], error) {
+	d := &decoder[V]{codec: c}
+	if br, ok := r.(byteReader); ok {
+		d.r = br
+	} else {
+		d.r = bufio.NewReader(r)
+	}
+
+	hdr := d.bytes(len(encodeMagic) + 2)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if string(hdr[:len(encodeMagic)]) != encodeMagic {
+		return nil, ErrInvalidFormat
+	}
+	if v := hdr[len(encodeMagic)]; v != encodeVersion {
+		return nil, fmt.Errorf("trie: unsupported serialization version %d", v)
+	}
+	switch hdr[len(encodeMagic)+1] {
+	case 0:
+		return nil, nil
+	case 1:
+		return d.node(nil, true)
+	}
+	return nil, ErrInvalidFormat
+}
+
+type encoder[V any] struct {
+	w     *bufio.Writer
+	codec Codec[V]
+	buf   []byte
+	val   []byte
+	n     int64
+	err   error
+}
+
+func (e *encoder[V]) node(n *Node[V], depth int) {
+	if len(n.key) > maxKeyLen {
+		e.err = fmt.Errorf("trie: key of %d bytes is too long to serialize", len(n.key))
+		return
+	}
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(n.key)-depth))
+	e.buf = append(e.buf, n.key[depth:]...)
+	if n.hasValue() {
+		e.buf = append(e.buf, flagValue)
+		e.value(n.value)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(n.edges)))
+	e.flush()
+
+	for _, nd := range n.edges {
+		if e.err != nil {
+			return
+		}
+		e.node(nd, len(n.key))
+	}
+}
+
+func (e *encoder[V]) value(v V) {
+	var err error
+	if e.val, err = e.codec.AppendValue(e.val[:0], v); err != nil {
+		e.err = err
+		return
+	}
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(e.val)))
+	e.buf = append(e.buf, e.val...)
+}
+
+func (e *encoder[V]) flush() {
+	if e.err != nil {
+		return
+	}
+	var n int
+	n, e.err = e.w.Write(e.buf)
+	e.n += int64(n)
+	e.buf = e.buf[:0]
+}
+
+// byteReader is what the decoder reads from, so it never reads more than it
+// needs.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+type decoder[V any] struct {
+	r     byteReader
+	codec Codec[V]
+	buf   []byte
+	err   error
+}
+
+// node reads a node under parent. The key is appended to parent, so along a
+// path of first children every key shares one growing buffer; the node only
+// keeps a slice of it that can't be appended to, and later siblings copy the
+// parent's key rather than overwrite what the first one wrote.
+func (d *decoder[V]) node(parent Key, root bool) (*Node[V], error) {
+	n := d.length()
+	if d.err == nil && len(parent)+n > maxKeyLen {
+		return nil, ErrInvalidFormat
+	}
+	suffix := d.bytes(n)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !root && len(suffix) == 0 {
+		return nil, ErrInvalidFormat
+	}
+	buf := append(parent, suffix...)
+	key := buf[:len(buf):len(buf)]
+
+	var value V
+	var has bool
+	switch d.byte() {
+	case flagValue:
+		value, has = d.value(), true
+	case 0:
+	default:
+		return nil, ErrInvalidFormat
+	}
+	count := d.length()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if count > 256 || !root && !has && count < 2 {
+		return nil, ErrInvalidFormat
+	}
+
+	var es edges[V]
+	if count > 0 {
+		es = make(edges[V], count)
+		for i := range es {
+			if i > 0 {
+				buf = key
+			}
+			nd, err := d.node(buf, false)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 && es[i-1].key[len(key)] >= nd.key[len(key)] {
+				return nil, ErrInvalidFormat
+			}
+			es[i] = nd
+		}
+	}
+	return (*Txn[V])(nil).newNode(key, value, has, es), nil
+}
+
+func (d *decoder[V]) value() (v V) {
+	b := d.bytes(d.length())
+	if d.err != nil {
+		return
+	}
+	v, d.err = d.codec.DecodeValue(b)
+	return
+}
+
+func (d *decoder[V]) length() int {
+	if d.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(d.r)
+	if err == nil && n > 1<<31 {
+		err = ErrInvalidFormat
+	}
+	d.setErr(err)
+	return int(n)
+}
+
+func (d *decoder[V]) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	c, err := d.r.ReadByte()
+	d.setErr(err)
+	return c
+}
+
+// decodeChunk is how far the buffer may grow ahead of the data read into it,
+// so a bogus length in truncated or hostile input can't force a huge
+// allocation.
+const decodeChunk = 64 << 10
+
+// bytes reads n bytes into a buffer that is only valid until the next read.
+func (d *decoder[V]) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	d.buf = d.buf[:0]
+	for len(d.buf) < n {
+		m := n - len(d.buf)
+		if m > decodeChunk {
+			m = decodeChunk
+		}
+		i := len(d.buf)
+		d.buf = append(d.buf, make([]byte, m)...)
+		if _, err := io.ReadFull(d.r, d.buf[i:]); err != nil {
+			d.setErr(err)
+			return nil
+		}
+	}
+	return d.buf
+}
+
+func (d *decoder[V]) setErr(err error) {
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		d.err = ErrInvalidFormat
+	default:
+		d.err = err
+	}
+}
@@ -0,0 +1,251 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteToReadNode(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var buf bytes.Buffer
+	if size, err := n.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	} else if size != int64(buf.Len()) {
+		t.Errorf("expected WriteTo to report %d bytes, got %d", buf.Len(), size)
+	}
+
+	m, err := ReadNode[int](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(n, m) {
+		t.Errorf("expected %#v, got %#v", n, m)
+	}
+	if m.Len() != n.Len() {
+		t.Errorf("expected Len to be %d, got %d", n.Len(), m.Len())
+	}
+}
+
+func TestWriteToReadNodeEmpty(t *testing.T) {
+	var (
+		n   *Node[string]
+		buf bytes.Buffer
+	)
+	if _, err := n.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := ReadNode[string](&buf); err != nil || m != nil {
+		t.Errorf("expected an empty trie, got %#v, %v", m, err)
+	}
+}
+
+func TestReadNodeStream(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	m := buildIterTrie([]string{"x", "y"})
+
+	var buf bytes.Buffer
+	n.WriteTo(&buf)
+	m.WriteTo(&buf)
+	buf.WriteString("rest")
+
+	for _, want := range []*Node[int]{n, m} {
+		got, err := ReadNode[int](&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(got, want) {
+			t.Errorf("expected %#v, got %#v", want, got)
+		}
+	}
+	if rest := buf.String(); rest != "rest" {
+		t.Errorf("expected ReadNode to stop at the end of the trie, left %q", rest)
+	}
+}
+
+type decimalCodec struct{}
+
+func (decimalCodec) AppendValue(b []byte, v int) ([]byte, error) {
+	return strconv.AppendInt(b, int64(v), 10), nil
+}
+
+func (decimalCodec) DecodeValue(b []byte) (int, error) {
+	return strconv.Atoi(string(b))
+}
+
+func TestWriteToCodec(t *testing.T) {
+	n := (*Node[int])(nil).PutString("foo", 42).PutString("foobar", 7)
+
+	var buf bytes.Buffer
+	if _, err := n.WriteToCodec(&buf, decimalCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("\x0242")) {
+		t.Errorf("expected the value to be written with the codec: %q", buf.Bytes())
+	}
+	m, err := ReadNodeCodec[int](&buf, decimalCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(n, m) {
+		t.Errorf("expected %#v, got %#v", n, m)
+	}
+}
+
+func TestReadNodeInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	(*Node[[]byte])(nil).PutString("foo", []byte("bar")).PutString("fob", []byte("baz")).WriteTo(&buf)
+	data := buf.Bytes()
+
+	for i := 0; i < len(data); i++ {
+		if _, err := ReadNode[[]byte](bytes.NewReader(data[:i])); err != ErrInvalidFormat {
+			t.Errorf("expected ErrInvalidFormat for %d bytes, got %v", i, err)
+		}
+	}
+	if _, err := ReadNode[[]byte](bytes.NewReader([]byte("nope\x01\x01"))); err != ErrInvalidFormat {
+		t.Errorf("expected ErrInvalidFormat for a bad magic string, got %v", err)
+	}
+	if _, err := ReadNode[[]byte](bytes.NewReader([]byte("trie\x09\x01"))); err == nil {
+		t.Errorf("expected an error for an unknown version")
+	}
+
+	// Nodes below the root need a key suffix, and a value or two edges.
+	for _, node := range []string{"\x01a\x00\x00", "\x01a\x00\x01\x01b\x01\x00\x00", "\x00\x01\x00\x00"} {
+		data := "trie\x01\x01\x00\x00\x01" + node
+		if _, err := ReadNode[[]byte](strings.NewReader(data)); err != ErrInvalidFormat {
+			t.Errorf("expected ErrInvalidFormat for %q, got %v", data, err)
+		}
+	}
+}
+
+func TestReadNodeHugeLength(t *testing.T) {
+	// A suffix claiming to be 256 MB long, with nothing after it.
+	data := binary.AppendUvarint([]byte("trie\x01\x01"), 1<<28)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := ReadNode[[]byte](bytes.NewReader(data)); err != ErrInvalidFormat {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("expected a truncated input to allocate little, got %d bytes", n)
+	}
+}
+
+// chainInput returns a serialized trie of depth nodes under the root, each
+// holding an empty value and adding one byte to the key of its parent.
+func chainInput(depth int) []byte {
+	data := []byte("trie\x01\x01\x00\x01\x00\x01")
+	for i := 0; i < depth; i++ {
+		last := byte(1)
+		if i == depth-1 {
+			last = 0
+		}
+		data = append(data, 1, 'a', flagValue, 0, last)
+	}
+	return data
+}
+
+func TestReadNodeDeepChain(t *testing.T) {
+	data := chainInput(maxKeyLen)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	n, err := ReadNode[[]byte](bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Len() != maxKeyLen+1 {
+		t.Errorf("expected %d values, got %d", maxKeyLen+1, n.Len())
+	}
+	if _, ok := n.Lookup(bytes.Repeat([]byte("a"), maxKeyLen)); !ok {
+		t.Errorf("expected the deepest key to be found")
+	}
+	// The keys share their bytes, rather than each holding a copy.
+	if m := after.TotalAlloc - before.TotalAlloc; m > 8<<20 {
+		t.Errorf("expected the keys to share memory, allocated %d bytes", m)
+	}
+
+	if _, err := ReadNode[[]byte](bytes.NewReader(chainInput(maxKeyLen + 1))); err != ErrInvalidFormat {
+		t.Errorf("expected ErrInvalidFormat for a key over the limit, got %v", err)
+	}
+	long := (*Node[[]byte])(nil).Put(make([]byte, maxKeyLen+1), nil)
+	if _, err := long.WriteTo(new(bytes.Buffer)); err == nil {
+		t.Errorf("expected WriteTo to reject a key over the limit")
+	}
+}
+
+func TestReadNodeSiblings(t *testing.T) {
+	// Siblings must not overwrite the keys of the ones read before them.
+	n := buildIterTrie([]string{"a", "ab", "abc", "abd", "ac", "b", "ba"})
+	var buf bytes.Buffer
+	if _, err := n.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadNode[int](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(n, m) {
+		t.Errorf("expected %#v, got %#v", n, m)
+	}
+	m = m.PutString("abe", 8).PutString("abca", 9)
+	if m.GetString("abc") != 3 || m.GetString("abd") != 4 || m.GetString("ac") != 5 {
+		t.Errorf("expected puts to leave the decoded keys alone, got %#v", m)
+	}
+}
+
+func TestWriteToReadNodeUntyped(t *testing.T) {
+	// There is no default encoding for interface values.
+	if _, err := foodTrie.WriteTo(new(bytes.Buffer)); err == nil {
+		t.Errorf("expected an error without a codec")
+	}
+}
+
+type (
+	level  int8
+	weight float32
+	name   string
+)
+
+func testDefaultCodec[V any](t *testing.T, v V, want string) {
+	t.Helper()
+	c := DefaultCodec[V]()
+	b, err := c.AppendValue(nil, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("expected %v to encode as %q, got %q", v, want, b)
+	}
+	if got, err := c.DecodeValue(b); err != nil || any(got) != any(v) {
+		t.Errorf("expected %q to decode to %v, got %v, %v", b, v, got, err)
+	}
+}
+
+func TestDefaultCodec(t *testing.T) {
+	testDefaultCodec(t, true, "\x01")
+	testDefaultCodec(t, -2, "\x03")
+	testDefaultCodec(t, uint16(300), "\xac\x02")
+	testDefaultCodec(t, level(-1), "\x01")
+	testDefaultCodec(t, weight(1), "\x00\x00\x80\x3f")
+	testDefaultCodec(t, 0.5, "\x00\x00\x00\x00\x00\x00\xe0\x3f")
+	testDefaultCodec(t, name("bob"), "bob")
+
+	if _, err := DefaultCodec[level]().DecodeValue([]byte("\x80\x02")); err != ErrInvalidFormat {
+		t.Errorf("expected an out of range value to fail, got %v", err)
+	}
+	if _, err := DefaultCodec[bool]().DecodeValue(nil); err != ErrInvalidFormat {
+		t.Errorf("expected an empty bool to fail, got %v", err)
+	}
+	if _, err := DefaultCodec[struct{ A int }]().AppendValue(nil, struct{ A int }{1}); err == nil {
+		t.Errorf("expected a struct to need a codec")
+	}
+}
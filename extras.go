@@ -5,7 +5,7 @@ import (
 	"strings"
 )
 
-func (es edges) GoString() string {
+func (es edges[V]) GoString() string {
 	if es == nil {
 		return "edges(nil)"
 	}
@@ -20,7 +20,7 @@ func (es edges) GoString() string {
 	return "edges{\n\t" + strings.Join(strs, ",\n\t") + ",\n}"
 }
 
-func (es edges) valid() bool {
+func (es edges[V]) valid() bool {
 	for _, e := range es {
 		if e == nil {
 			return false
@@ -29,31 +29,31 @@ func (es edges) valid() bool {
 	return true
 }
 
-func (n *Node) GoString() string {
+func (n *Node[V]) GoString() string {
 	if n == nil {
 		return "(*Node)(nil)"
 	}
 	es := strings.Replace(n.edges.GoString(), "\n", "\n\t", -1)
-	if n.value == nil {
+	if !n.hasValue() {
 		return fmt.Sprintf("&Node{\n\tkey:   Key(%q),\n\tedges: %s,\n}", n.key, es)
 	}
 	return fmt.Sprintf("&Node{\n\tkey:   Key(%q),\n\tvalue: %#v,\n\tedges: %s,\n}", n.key, n.value, es)
 }
 
-func (n *Node) Histogram() map[uint8]int {
+func (n *Node[V]) Histogram() map[uint8]int {
 	h := make(map[uint8]int, 256)
 	n.histogram(h)
 	return h
 }
 
-func (n *Node) histogram(h map[uint8]int) {
+func (n *Node[V]) histogram(h map[uint8]int) {
 	h[uint8(len(n.edges))]++
 	for _, nd := range n.edges {
 		nd.histogram(h)
 	}
 }
 
-func (t *Txn) PrintHistogram() {
+func (t *Txn[V]) PrintHistogram() {
 	// fmt.Printf("mutable nodes: %d, new nodes: %d\n", len(t.mut), t.newNodes)
 
 	h := t.root.Histogram()
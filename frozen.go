@@ -0,0 +1,372 @@
+package trie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// A frozen trie is a flat, read-only layout that is queried in place, without
+// building any nodes. Nodes are written children first, so every edge points
+// back towards the start of the data, and the root is found through a footer
+// at the very end. Each node is laid out as:
+//
+//	uint32          length of the key suffix (the key minus the parent's key)
+//	uint32          length of the value plus one, or zero if there is none
+//	uint64          number of values in the subtree
+//	uint16          number of edges
+//	[n]byte         first byte of each edge's key suffix, in order
+//	[n]uint64       offset of each edge
+//	bytes           key suffix
+//	bytes           value
+//
+// The footer is the offset of the root as a uint64, the magic string "trif"
+// and a version byte, padded to 16 bytes. All integers are little endian.
+const (
+	frozenMagic      = "trif"
+	frozenVersion    = 1
+	frozenFooterSize = 16
+	frozenNodeSize   = 4 + 4 + 8 + 2
+	frozenNoRoot     = ^uint64(0)
+)
+
+// Freeze writes n to w in the frozen layout, encoding values with
+// DefaultCodec.
+func (n *Node[V]) Freeze(w io.Writer) error {
+	return n.FreezeCodec(w, DefaultCodec[V]())
+}
+
+// FreezeCodec is like Freeze, but encodes values with c.
+func (n *Node[V]) FreezeCodec(w io.Writer, c Codec[V]) error {
+	fw := &freezer[V]{w: bufio.NewWriter(w), codec: c}
+	root := frozenNoRoot
+	if n != nil {
+		root = fw.node(n, 0)
+	}
+	fw.buf = binary.LittleEndian.AppendUint64(fw.buf[:0], root)
+	fw.buf = append(fw.buf, frozenMagic...)
+	fw.buf = append(fw.buf, frozenVersion, 0, 0, 0)
+	fw.write()
+	if fw.err != nil {
+		return fw.err
+	}
+	return fw.w.Flush()
+}
+
+type freezer[V any] struct {
+	w     *bufio.Writer
+	codec Codec[V]
+	buf   []byte
+	val   []byte
+	off   uint64
+	err   error
+}
+
+// node writes n and everything under it, returning the offset of n.
+func (fw *freezer[V]) node(n *Node[V], depth int) uint64 {
+	offs := make([]uint64, len(n.edges))
+	for i, nd := range n.edges {
+		offs[i] = fw.node(nd, len(n.key))
+	}
+	if fw.err != nil {
+		return 0
+	}
+
+	vlen := uint32(0)
+	if n.hasValue() {
+		if fw.val, fw.err = fw.codec.AppendValue(fw.val[:0], n.value); fw.err != nil {
+			return 0
+		}
+		vlen = uint32(len(fw.val)) + 1
+	}
+
+	b := fw.buf[:0]
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(n.key)-depth))
+	b = binary.LittleEndian.AppendUint32(b, vlen)
+	b = binary.LittleEndian.AppendUint64(b, uint64(n.size))
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(n.edges)))
+	for _, nd := range n.edges {
+		b = append(b, nd.key[len(n.key)])
+	}
+	for _, off := range offs {
+		b = binary.LittleEndian.AppendUint64(b, off)
+	}
+	b = append(b, n.key[depth:]...)
+	if vlen > 0 {
+		b = append(b, fw.val...)
+	}
+	fw.buf = b
+
+	off := fw.off
+	fw.write()
+	return off
+}
+
+func (fw *freezer[V]) write() {
+	if fw.err != nil {
+		return
+	}
+	var n int
+	n, fw.err = fw.w.Write(fw.buf)
+	fw.off += uint64(n)
+}
+
+// Frozen is a read-only trie queried directly from its frozen layout. Keys
+// and values returned by it point into the underlying data and must not be
+// modified; if the data is memory mapped, they are only valid until Close.
+//
+// A Frozen is safe for concurrent use. Nodes are checked as they are read, so
+// corrupt data does not cause a panic: a node that does not fit in the data,
+// or an edge that does not point back towards the start, reads as an empty
+// node, and the keys under it are reported missing.
+type Frozen struct {
+	data  []byte
+	root  uint64
+	base  []byte // key of the root's parent
+	close func() error
+}
+
+// NewFrozen returns a Frozen reading from data, as written by Freeze. It only
+// reads the footer and the root, so it takes the same time however large the
+// data is.
+func NewFrozen(data []byte) (*Frozen, error) {
+	if len(data) < frozenFooterSize {
+		return nil, ErrInvalidFormat
+	}
+	footer := data[len(data)-frozenFooterSize:]
+	if string(footer[8:12]) != frozenMagic {
+		return nil, ErrInvalidFormat
+	}
+	if v := footer[12]; v != frozenVersion {
+		return nil, fmt.Errorf("trie: unsupported frozen version %d", v)
+	}
+	f := &Frozen{
+		data: data[:len(data)-frozenFooterSize],
+		root: binary.LittleEndian.Uint64(footer),
+	}
+	if f.root != frozenNoRoot {
+		if _, ok := f.nodeLen(f.root); !ok {
+			return nil, ErrInvalidFormat
+		}
+	}
+	return f, nil
+}
+
+// nodeLen returns the length of the node at off, and whether it fits in the
+// data.
+func (f *Frozen) nodeLen(off uint64) (uint64, bool) {
+	if off >= uint64(len(f.data)) {
+		return 0, false
+	}
+	rest := uint64(len(f.data)) - off
+	if rest < frozenNodeSize {
+		return 0, false
+	}
+	b := f.data[off:]
+	slen := uint64(binary.LittleEndian.Uint32(b))
+	vlen := uint64(binary.LittleEndian.Uint32(b[4:]))
+	n := uint64(binary.LittleEndian.Uint16(b[16:]))
+	if n > 256 {
+		return 0, false
+	}
+	size := frozenNodeSize + 9*n + slen
+	if vlen > 0 {
+		size += vlen - 1
+	}
+	return size, size <= rest
+}
+
+// OpenFrozen maps the file at path into memory and returns a Frozen reading
+// from it. The file must not be modified while it is open.
+func OpenFrozen(path string) (*Frozen, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFrozen(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	f.close = unmap
+	return f, nil
+}
+
+// Close releases the data of a Frozen returned by OpenFrozen. It does
+// nothing for other Frozen values.
+func (f *Frozen) Close() error {
+	if f == nil || f.close == nil {
+		return nil
+	}
+	err := f.close()
+	f.close = nil
+	return err
+}
+
+// Len returns the number of values in the trie.
+func (f *Frozen) Len() int {
+	if f == nil || f.root == frozenNoRoot {
+		return 0
+	}
+	return int(f.node(f.root).size)
+}
+
+// Get returns the value for k.
+func (f *Frozen) Get(k []byte) ([]byte, bool) {
+	if f == nil || f.root == frozenNoRoot || !bytes.HasPrefix(k, f.base) {
+		return nil, false
+	}
+	for i, off := len(f.base), f.root; ; {
+		nd := f.node(off)
+		end := i + len(nd.suffix)
+
+		if len(k) < end || !bytes.Equal(nd.suffix, k[i:end]) {
+			return nil, false
+		}
+		if len(k) == end {
+			return nd.value, nd.hasValue
+		}
+		i = end
+
+		j, ok := nd.get(k[i])
+		if !ok {
+			return nil, false
+		}
+		off = nd.edge(j)
+	}
+}
+
+// GetString is like Get, but takes a string key.
+func (f *Frozen) GetString(k string) ([]byte, bool) {
+	return f.Get([]byte(k))
+}
+
+// Prefix returns the subtree holding every key that starts with p, or nil if
+// there are none. The result shares its data with f and does not need to be
+// closed. Like a nil *Node, a nil *Frozen is an empty trie.
+func (f *Frozen) Prefix(p []byte) *Frozen {
+	if f == nil || f.root == frozenNoRoot {
+		return nil
+	}
+	if len(p) <= len(f.base) {
+		if bytes.HasPrefix(f.base, p) {
+			return f.view(f.root, f.base)
+		}
+		return nil
+	}
+	if !bytes.HasPrefix(p, f.base) {
+		return nil
+	}
+	for i, off := len(f.base), f.root; ; {
+		nd := f.node(off)
+		end := i + len(nd.suffix)
+
+		if len(p) <= end {
+			if bytes.HasPrefix(nd.suffix, p[i:]) {
+				return f.view(off, bytes.Clone(p[:i]))
+			}
+			return nil
+		}
+		if !bytes.Equal(nd.suffix, p[i:end]) {
+			return nil
+		}
+		i = end
+
+		j, ok := nd.get(p[i])
+		if !ok {
+			return nil
+		}
+		off = nd.edge(j)
+	}
+}
+
+// PrefixString is like Prefix, but takes a string prefix.
+func (f *Frozen) PrefixString(p string) *Frozen {
+	return f.Prefix([]byte(p))
+}
+
+// Walk calls fn for every key and value, in key order, until fn returns
+// false. The key is only valid until fn returns.
+func (f *Frozen) Walk(fn func(key, value []byte) bool) {
+	if f == nil || f.root == frozenNoRoot {
+		return
+	}
+	f.walk(f.root, append([]byte(nil), f.base...), fn)
+}
+
+func (f *Frozen) walk(off uint64, key []byte, fn func(key, value []byte) bool) bool {
+	nd := f.node(off)
+	key = append(key, nd.suffix...)
+	if nd.hasValue && !fn(key, nd.value) {
+		return false
+	}
+	for i := 0; i < len(nd.labels); i++ {
+		if !f.walk(nd.edge(i), key, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Frozen) view(root uint64, base []byte) *Frozen {
+	return &Frozen{data: f.data, root: root, base: base}
+}
+
+// frozenNode is a node of a frozen trie, sliced out of the data in place.
+type frozenNode struct {
+	off      uint64
+	suffix   []byte
+	value    []byte
+	hasValue bool
+	size     uint64
+	labels   []byte
+	offs     []byte
+}
+
+// node reads the node at off, or returns an empty node if it does not fit in
+// the data.
+func (f *Frozen) node(off uint64) (nd frozenNode) {
+	if _, ok := f.nodeLen(off); !ok {
+		return
+	}
+	nd.off = off
+	b := f.data[off:]
+	slen := binary.LittleEndian.Uint32(b)
+	vlen := binary.LittleEndian.Uint32(b[4:])
+	nd.size = binary.LittleEndian.Uint64(b[8:])
+	n := int(binary.LittleEndian.Uint16(b[16:]))
+
+	b = b[frozenNodeSize:]
+	nd.labels, b = b[:n], b[n:]
+	nd.offs, b = b[:8*n], b[8*n:]
+	nd.suffix, b = b[:slen], b[slen:]
+	if vlen > 0 {
+		nd.value, nd.hasValue = b[:vlen-1], true
+	}
+	return
+}
+
+// get returns the index of the edge starting with label.
+func (nd *frozenNode) get(label byte) (int, bool) {
+	i := nd.search(label)
+	return i, i < len(nd.labels) && nd.labels[i] == label
+}
+
+// search returns the index of the first edge starting at or after label.
+func (nd *frozenNode) search(label byte) int {
+	return sort.Search(len(nd.labels), func(i int) bool { return nd.labels[i] >= label })
+}
+
+// edge returns the offset of the node at the end of the i'th edge. Freeze
+// writes children before their parents, so an edge pointing anywhere else is
+// corrupt and leads to frozenNoRoot, which reads as an empty node. This also
+// keeps a corrupt layout from looping back on itself.
+func (nd *frozenNode) edge(i int) uint64 {
+	if off := binary.LittleEndian.Uint64(nd.offs[8*i:]); off < nd.off {
+		return off
+	}
+	return frozenNoRoot
+}
@@ -0,0 +1,243 @@
+package trie
+
+// FrozenIterator walks the values of a Frozen in key order, in either
+// direction. It works like Iterator, reading nodes in place.
+type FrozenIterator struct {
+	f     *Frozen
+	stack []frozenFrame
+	key   []byte
+}
+
+type frozenFrame struct {
+	off   uint64
+	index int // index in the parent's edges
+	depth int // length of the parent's key
+}
+
+// Iterator returns a new FrozenIterator over f.
+func (f *Frozen) Iterator() *FrozenIterator {
+	return &FrozenIterator{f: f}
+}
+
+// Valid reports whether the iterator is positioned at a value.
+func (it *FrozenIterator) Valid() bool {
+	return len(it.stack) > 0
+}
+
+// Key returns the key at the current position, or nil if the iterator is not
+// valid. The key is only valid until the iterator moves.
+func (it *FrozenIterator) Key() []byte {
+	if !it.Valid() {
+		return nil
+	}
+	return it.key
+}
+
+// Value returns the value at the current position, or nil if the iterator is
+// not valid.
+func (it *FrozenIterator) Value() []byte {
+	if !it.Valid() {
+		return nil
+	}
+	return it.node().value
+}
+
+// First moves to the smallest key, returning false if there is none.
+func (it *FrozenIterator) First() bool {
+	if !it.reset() {
+		return false
+	}
+	return it.forward()
+}
+
+// Last moves to the largest key, returning false if there is none.
+func (it *FrozenIterator) Last() bool {
+	if !it.reset() {
+		return false
+	}
+	it.descendLast()
+	return it.backward()
+}
+
+// Next moves to the following key, returning false if there is none.
+func (it *FrozenIterator) Next() bool {
+	if !it.Valid() || !it.stepNext() {
+		return false
+	}
+	return it.forward()
+}
+
+// Prev moves to the preceding key, returning false if there is none.
+func (it *FrozenIterator) Prev() bool {
+	if !it.Valid() || !it.stepPrev() {
+		return false
+	}
+	return it.backward()
+}
+
+// SeekGE moves to the smallest key greater than or equal to k, returning
+// false if there is none.
+func (it *FrozenIterator) SeekGE(k []byte) bool {
+	if !it.reset() {
+		return false
+	}
+	for depth := 0; ; {
+		d, short := Key(it.key).commonBytesLen(k, depth)
+		switch {
+		case d == len(k):
+			return it.forward()
+		case short:
+			if it.key[d] > k[d] {
+				return it.forward()
+			}
+			return it.skip() && it.forward()
+		}
+		depth = d
+
+		nd := it.node()
+		i := nd.search(k[depth])
+		switch {
+		case i < len(nd.labels) && nd.labels[i] == k[depth]:
+			it.push(nd.edge(i), i)
+		case i < len(nd.labels):
+			it.push(nd.edge(i), i)
+			return it.forward()
+		default:
+			return it.skip() && it.forward()
+		}
+	}
+}
+
+// SeekLE moves to the largest key less than or equal to k, returning false if
+// there is none.
+func (it *FrozenIterator) SeekLE(k []byte) bool {
+	if !it.reset() {
+		return false
+	}
+	for depth := 0; ; {
+		d, short := Key(it.key).commonBytesLen(k, depth)
+		switch {
+		case d == len(k):
+			if len(it.key) == len(k) {
+				return it.backward()
+			}
+			return it.stepPrev() && it.backward()
+		case short:
+			if it.key[d] > k[d] {
+				return it.stepPrev() && it.backward()
+			}
+			it.descendLast()
+			return it.backward()
+		}
+		depth = d
+
+		nd := it.node()
+		i, ok := nd.get(k[depth])
+		switch {
+		case ok:
+			it.push(nd.edge(i), i)
+		case i > 0:
+			it.push(nd.edge(i-1), i-1)
+			it.descendLast()
+			return it.backward()
+		default:
+			return it.backward()
+		}
+	}
+}
+
+// SeekGEString is like SeekGE, but takes a string key.
+func (it *FrozenIterator) SeekGEString(k string) bool {
+	return it.SeekGE([]byte(k))
+}
+
+// SeekLEString is like SeekLE, but takes a string key.
+func (it *FrozenIterator) SeekLEString(k string) bool {
+	return it.SeekLE([]byte(k))
+}
+
+func (it *FrozenIterator) node() frozenNode {
+	return it.f.node(it.stack[len(it.stack)-1].off)
+}
+
+func (it *FrozenIterator) push(off uint64, i int) {
+	it.stack = append(it.stack, frozenFrame{off: off, index: i, depth: len(it.key)})
+	it.key = append(it.key, it.f.node(off).suffix...)
+}
+
+func (it *FrozenIterator) pop() frozenFrame {
+	top := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	it.key = it.key[:top.depth]
+	return top
+}
+
+func (it *FrozenIterator) reset() bool {
+	it.stack = it.stack[:0]
+	if it.f == nil || it.f.root == frozenNoRoot {
+		return false
+	}
+	it.key = append(it.key[:0], it.f.base...)
+	it.push(it.f.root, 0)
+	return true
+}
+
+func (it *FrozenIterator) forward() bool {
+	for it.Valid() {
+		if it.node().hasValue {
+			return true
+		}
+		it.stepNext()
+	}
+	return false
+}
+
+func (it *FrozenIterator) backward() bool {
+	for it.Valid() {
+		if it.node().hasValue {
+			return true
+		}
+		it.stepPrev()
+	}
+	return false
+}
+
+func (it *FrozenIterator) stepNext() bool {
+	if nd := it.node(); len(nd.labels) > 0 {
+		it.push(nd.edge(0), 0)
+		return true
+	}
+	return it.skip()
+}
+
+func (it *FrozenIterator) skip() bool {
+	for len(it.stack) > 1 {
+		top := it.pop()
+		if nd, i := it.node(), top.index+1; i < len(nd.labels) {
+			it.push(nd.edge(i), i)
+			return true
+		}
+	}
+	it.stack = it.stack[:0]
+	return false
+}
+
+func (it *FrozenIterator) stepPrev() bool {
+	if len(it.stack) < 2 {
+		it.stack = it.stack[:0]
+		return false
+	}
+	top := it.pop()
+	if nd, i := it.node(), top.index-1; i >= 0 {
+		it.push(nd.edge(i), i)
+		it.descendLast()
+	}
+	return true
+}
+
+func (it *FrozenIterator) descendLast() {
+	for nd := it.node(); len(nd.labels) > 0; nd = it.node() {
+		i := len(nd.labels) - 1
+		it.push(nd.edge(i), i)
+	}
+}
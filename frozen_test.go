@@ -0,0 +1,211 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func freezeTrie(t *testing.T, n *Node[int]) *Frozen {
+	var buf bytes.Buffer
+	if err := n.FreezeCodec(&buf, decimalCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFrozen(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFrozenGet(t *testing.T) {
+	f := freezeTrie(t, buildIterTrie(iterKeys))
+
+	if l := f.Len(); l != len(iterKeys) {
+		t.Errorf("expected Len to be %d, got %d", len(iterKeys), l)
+	}
+	for i, k := range iterKeys {
+		if v, ok := f.GetString(k); !ok || string(v) != strconv.Itoa(i+1) {
+			t.Errorf("expected %q to be %d, got %q", k, i+1, v)
+		}
+	}
+	for _, k := range []string{"aa", "abcd", "fo", "foo b", "zz"} {
+		if v, ok := f.GetString(k); ok {
+			t.Errorf("expected %q to not be found, got %q", k, v)
+		}
+	}
+}
+
+func TestFrozenPrefixAndWalk(t *testing.T) {
+	f := freezeTrie(t, buildIterTrie(iterKeys))
+
+	walk := func(f *Frozen) []string {
+		var keys []string
+		f.Walk(func(k, v []byte) bool {
+			keys = append(keys, string(k))
+			return true
+		})
+		return keys
+	}
+
+	if keys := walk(f); !equalStrings(keys, iterKeys) {
+		t.Errorf("expected %q, got %q", iterKeys, keys)
+	}
+
+	p := f.PrefixString("foo")
+	if want := []string{"foo", "foo bar", "foo baz", "food"}; !equalStrings(walk(p), want) {
+		t.Errorf("expected %q, got %q", want, walk(p))
+	}
+	if p.Len() != 4 {
+		t.Errorf("expected the prefix to hold 4 values, got %d", p.Len())
+	}
+	if v, ok := p.GetString("foo baz"); !ok || string(v) != "12" {
+		t.Errorf(`expected "foo baz" to be 12, got %q`, v)
+	}
+	if _, ok := p.GetString("abc"); ok {
+		t.Errorf(`expected "abc" to be outside of the prefix`)
+	}
+	if q := p.PrefixString("foo ba"); q == nil || !equalStrings(walk(q), []string{"foo bar", "foo baz"}) {
+		t.Errorf("expected a nested prefix to narrow the subtree")
+	}
+	if f.PrefixString("fox") != nil || p.PrefixString("a") != nil {
+		t.Errorf("expected Prefix to return nil for unknown prefixes")
+	}
+
+	// Like a nil *Node, the nil result is an empty trie.
+	none := f.PrefixString("fox")
+	if _, ok := none.GetString("fox"); ok || none.Len() != 0 || none.PrefixString("") != nil || none.Iterator().First() || none.Iterator().SeekLEString("z") {
+		t.Errorf("expected a nil Frozen to be empty")
+	}
+	if err := none.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFrozenIterator(t *testing.T) {
+	keys := iterKeys[1:]
+	it := freezeTrie(t, buildIterTrie(keys)).Iterator()
+
+	var got []string
+	for ok := it.Last(); ok; ok = it.Prev() {
+		got = append([]string{string(it.Key())}, got...)
+	}
+	if !equalStrings(got, keys) {
+		t.Errorf("expected %q, got %q", keys, got)
+	}
+
+	for _, p := range []string{"", "a", "aa", "abz", "b", "bz", "foo", "foo bb", "zz"} {
+		i := sort.SearchStrings(keys, p)
+		if ok := it.SeekGEString(p); ok != (i < len(keys)) || ok && string(it.Key()) != keys[i] {
+			t.Errorf("SeekGE(%q): got %q", p, it.Key())
+		}
+		j := sort.Search(len(keys), func(i int) bool { return keys[i] > p }) - 1
+		if ok := it.SeekLEString(p); ok != (j >= 0) || ok && string(it.Key()) != keys[j] {
+			t.Errorf("SeekLE(%q): got %q", p, it.Key())
+		}
+	}
+
+	if !it.SeekGEString("b") || !it.Next() || string(it.Value()) != "6" {
+		t.Errorf(`expected the key after "b" to hold 6, got %q`, it.Value())
+	}
+}
+
+func TestOpenFrozen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trie")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := (*Node[string])(nil).PutString("foo", "bar").PutString("fob", "baz")
+	if err := n.Freeze(file); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	f, err := OpenFrozen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if v, ok := f.GetString("fob"); !ok || string(v) != "baz" {
+		t.Errorf(`expected "fob" to be "baz", got %q`, v)
+	}
+}
+
+func TestFrozenEmpty(t *testing.T) {
+	f := freezeTrie(t, nil)
+
+	if f.Len() != 0 || f.PrefixString("") != nil || f.Iterator().First() {
+		t.Errorf("expected an empty frozen trie")
+	}
+	if _, ok := f.GetString(""); ok {
+		t.Errorf("expected an empty frozen trie")
+	}
+	if _, err := NewFrozen([]byte("not a frozen trie")); err != ErrInvalidFormat {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestFrozenCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	if err := buildIterTrie(iterKeys).FreezeCodec(&buf, decimalCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	probes := append([]string{"0", "aa", "abz", "fo", "fooz", "z"}, iterKeys...)
+	for i := range data {
+		for _, mask := range []byte{0x01, 0x80, 0xff} {
+			data[i] ^= mask
+			f, err := NewFrozen(bytes.Clone(data))
+			data[i] ^= mask
+			if err != nil {
+				continue
+			}
+
+			// Whatever got through must be safe to query.
+			f.Len()
+			f.Walk(func(key, value []byte) bool { return true })
+			it := f.Iterator()
+			for ok := it.First(); ok; ok = it.Next() {
+			}
+			for ok := it.Last(); ok; ok = it.Prev() {
+			}
+			for _, p := range probes {
+				f.GetString(p)
+				if sub := f.PrefixString(p); sub != nil {
+					sub.Walk(func(key, value []byte) bool { return true })
+				}
+				it.SeekGEString(p)
+				it.SeekLEString(p)
+			}
+		}
+	}
+}
+
+func TestFrozenCorruptNode(t *testing.T) {
+	var buf bytes.Buffer
+	n := (*Node[string])(nil).PutString("a", "1").PutString("b", "2")
+	if err := n.Freeze(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// The leaf for "a" is written first; make its key run past the end.
+	binary.LittleEndian.PutUint32(data, ^uint32(0))
+	f, err := NewFrozen(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.GetString("a"); ok {
+		t.Errorf(`expected "a" to read as missing`)
+	}
+	if v, ok := f.GetString("b"); !ok || string(v) != "2" {
+		t.Errorf(`expected "b" to be "2", got %q, %v`, v, ok)
+	}
+}
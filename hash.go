@@ -0,0 +1,147 @@
+package trie
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Digest is the SHA-256 content hash of a subtree. Tries holding the same keys
+// and values have the same digest however they were built, so comparing the
+// digests of two roots tells whether they differ, and comparing the digests
+// of their children tells where.
+//
+// A node's digest covers its key suffix (the key minus its parent's key), a
+// flag byte that is 1 if it holds a value, the encoded value if it does, and
+// the first byte and digest of each edge in order, with every variable length
+// part prefixed by its uvarint length. A node without a value and with a
+// single edge is skipped over, as if the edge hung from its parent, and an
+// empty trie has the zero Digest.
+type Digest [sha256.Size]byte
+
+// Hasher computes digests, encoding values with a Codec. Digests are cached on
+// the nodes, tagged with the Hasher that computed them, so hashing a trie that
+// shares nodes with one hashed before only visits the nodes that are new.
+// Keep one Hasher around rather than making a new one for every call.
+//
+// The codec must encode equal values to the same bytes. DefaultCodec does,
+// and depends only on the values, not on the names of their types, so hosts
+// agree on digests as long as they hold the same data.
+type Hasher[V any] struct {
+	codec Codec[V]
+}
+
+// NewHasher returns a Hasher that encodes values with c.
+func NewHasher[V any](c Codec[V]) *Hasher[V] {
+	return &Hasher[V]{codec: c}
+}
+
+// Hash returns the digest of n, encoding values with DefaultCodec. For value
+// types DefaultCodec has no encoding for, it fails; use a Hasher with a Codec.
+func (n *Node[V]) Hash() (Digest, error) {
+	return (*Hasher[V])(nil).Sum(n)
+}
+
+// Sum returns the digest of n. A nil Hasher encodes values with DefaultCodec.
+func (h *Hasher[V]) Sum(n *Node[V]) (Digest, error) {
+	return newSummer(h).node(n, 0)
+}
+
+// hashEntry is a digest cached on a node. A node can be reached at a new depth
+// once a delete collapses its parent, so the depth is part of the tag.
+type hashEntry struct {
+	h     any // the *Hasher that computed it
+	depth int
+	sum   Digest
+}
+
+type summer[V any] struct {
+	h     *Hasher[V]
+	codec Codec[V]
+	buf   []byte
+	val   []byte
+}
+
+func newSummer[V any](h *Hasher[V]) *summer[V] {
+	s := &summer[V]{h: h}
+	if h != nil {
+		s.codec = h.codec
+	} else {
+		s.codec = DefaultCodec[V]()
+	}
+	return s
+}
+
+func (s *summer[V]) node(n *Node[V], depth int) (Digest, error) {
+	if n = n.canonical(); n == nil {
+		return Digest{}, nil
+	}
+	if a := n.aux.Load(); a != nil {
+		if e := a.digest.Load(); e != nil && e.h == s.h && e.depth == depth {
+			return e.sum, nil
+		}
+	}
+
+	labels := make([]byte, len(n.edges))
+	sums := make([]Digest, len(n.edges))
+	for i, nd := range n.edges {
+		var err error
+		labels[i] = nd.key[len(n.key)]
+		if sums[i], err = s.node(nd, len(n.key)); err != nil {
+			return Digest{}, err
+		}
+	}
+
+	val, err := s.value(n)
+	if err != nil {
+		return Digest{}, err
+	}
+	s.buf = appendNodeHash(s.buf[:0], n.key[depth:], val, n.present, labels, sums)
+
+	sum := Digest(sha256.Sum256(s.buf))
+	n.getAux().digest.Store(&hashEntry{h: s.h, depth: depth, sum: sum})
+	return sum, nil
+}
+
+// value returns the encoded value of n, or nil if it has none. It is only
+// valid until the next call.
+func (s *summer[V]) value(n *Node[V]) ([]byte, error) {
+	if !n.present {
+		return nil, nil
+	}
+	var err error
+	s.val, err = s.codec.AppendValue(s.val[:0], n.value)
+	return s.val, err
+}
+
+// canonical skips over nodes without a value and with fewer than two edges.
+// Such nodes only come from how the trie was built, not from what it holds,
+// so they are left out of digests.
+func (n *Node[V]) canonical() *Node[V] {
+	for n != nil && !n.present && len(n.edges) < 2 {
+		if len(n.edges) == 0 {
+			return nil
+		}
+		n = n.edges[0]
+	}
+	return n
+}
+
+// appendNodeHash appends the bytes hashed for a node to b, given its encoded
+// value and the first byte and digest of each edge.
+func appendNodeHash(b, suffix, val []byte, has bool, labels []byte, sums []Digest) []byte {
+	b = binary.AppendUvarint(b, uint64(len(suffix)))
+	b = append(b, suffix...)
+	if has {
+		b = append(b, flagValue)
+		b = binary.AppendUvarint(b, uint64(len(val)))
+		b = append(b, val...)
+	} else {
+		b = append(b, 0)
+	}
+	b = binary.AppendUvarint(b, uint64(len(sums)))
+	for i := range sums {
+		b = append(b, labels[i])
+		b = append(b, sums[i][:]...)
+	}
+	return b
+}
@@ -0,0 +1,104 @@
+package trie
+
+import "testing"
+
+func mustHash(t *testing.T, n *Node[int]) Digest {
+	t.Helper()
+	d, err := n.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestHashSameContent(t *testing.T) {
+	a := buildIterTrie(iterKeys)
+
+	tx := new(Txn[int])
+	for i := len(iterKeys) - 1; i >= 0; i-- {
+		tx.PutString(iterKeys[i], i+1)
+	}
+	if mustHash(t, a) != mustHash(t, tx.Commit()) {
+		t.Errorf("expected tries with the same content to have the same digest")
+	}
+
+	// An empty root node is left over from how the trie was built.
+	x := new(Node[int]).PutString("foo", 1).PutString("bar", 2)
+	y := (*Node[int])(nil).PutString("bar", 2).PutString("foo", 1)
+	if mustHash(t, x) != mustHash(t, y) {
+		t.Errorf("expected an empty root node to be left out of the digest")
+	}
+	if mustHash(t, a) == (Digest{}) {
+		t.Errorf("expected a non-empty trie to have a non-zero digest")
+	}
+	if d := mustHash(t, nil); d != (Digest{}) {
+		t.Errorf("expected an empty trie to have the zero digest, got %x", d)
+	}
+}
+
+func TestHashDiverge(t *testing.T) {
+	a := buildIterTrie(iterKeys)
+	b := a.PutString("abd", 40)
+
+	if mustHash(t, a) == mustHash(t, b) {
+		t.Fatalf("expected a changed value to change the digest")
+	}
+	if mustHash(t, a.PrefixString("f")) != mustHash(t, b.PrefixString("f")) {
+		t.Errorf("expected an unchanged subtree to keep its digest")
+	}
+	if mustHash(t, a.PrefixString("ab")) == mustHash(t, b.PrefixString("ab")) {
+		t.Errorf("expected the changed subtree to have a new digest")
+	}
+
+	if mustHash(t, a.PutString("zz", 0)) == mustHash(t, a) {
+		t.Errorf("expected storing a zero value to change the digest")
+	}
+}
+
+func TestHashTxn(t *testing.T) {
+	tx := buildIterTrie(iterKeys).Txn()
+	tx.PutString("abe", 20)
+	before := mustHash(t, tx.Prefix(nil))
+
+	// The nodes are still mutable, so this changes them in place.
+	tx.PutString("abf", 21)
+	tx.DeleteString("abe")
+	after := mustHash(t, tx.Prefix(nil))
+
+	want := mustHash(t, buildIterTrie(iterKeys).PutString("abf", 21))
+	if after == before || after != want {
+		t.Errorf("expected writes in place to reset cached digests")
+	}
+}
+
+func TestHasherCodec(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	h := NewHasher[int](decimalCodec{})
+
+	d, err := h.Sum(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d == mustHash(t, n) {
+		t.Errorf("expected a different codec to give a different digest")
+	}
+	if again, _ := h.Sum(n); again != d {
+		t.Errorf("expected the digest to be stable")
+	}
+	if e := n.aux.Load().digest.Load(); e == nil || e.h != h {
+		t.Errorf("expected the digest to be cached for the hasher")
+	}
+}
+
+func TestHashDefaultCodec(t *testing.T) {
+	// The digest depends on the values, not on the name of their type.
+	a := mustHash(t, (*Node[int])(nil).PutString("foo", -1).PutString("bar", 2))
+	b, err := (*Node[level])(nil).PutString("foo", -1).PutString("bar", 2).Hash()
+	if err != nil || a != b {
+		t.Errorf("expected the same digest for int and level values, got %v", err)
+	}
+
+	if _, err := (*Node[any])(nil).PutString("foo", 1).Hash(); err == nil {
+		t.Errorf("expected hashing values without a default codec to fail")
+	}
+}
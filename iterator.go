@@ -0,0 +1,257 @@
+package trie
+
+// Iterator walks the values of a trie in key order, in either direction. It
+// keeps the path from the root to its current position, so moving between
+// neighbouring keys only touches the nodes in between.
+//
+// A new Iterator is not positioned; call First, Last, SeekGE or SeekLE before
+// reading from it. The zero Iterator is empty.
+type Iterator[V any] struct {
+	root  *Node[V]
+	stack []iterFrame[V]
+}
+
+// iterFrame is one step of the path to the current position: a node and its
+// index in the parent's edges.
+type iterFrame[V any] struct {
+	node  *Node[V]
+	index int
+}
+
+// Iterator returns a new Iterator over n.
+func (n *Node[V]) Iterator() *Iterator[V] {
+	return &Iterator[V]{root: n}
+}
+
+// Valid reports whether the iterator is positioned at a value.
+func (it *Iterator[V]) Valid() bool {
+	return len(it.stack) > 0
+}
+
+// Key returns the key at the current position, or nil if the iterator is not
+// valid. The returned slice must not be modified.
+func (it *Iterator[V]) Key() []byte {
+	if n := it.node(); n != nil {
+		return n.key
+	}
+	return nil
+}
+
+// Value returns the value at the current position, or the zero value if the
+// iterator is not valid.
+func (it *Iterator[V]) Value() V {
+	if n := it.node(); n != nil {
+		return n.value
+	}
+	var zero V
+	return zero
+}
+
+// First moves to the smallest key, returning false if the trie is empty.
+func (it *Iterator[V]) First() bool {
+	if !it.reset() {
+		return false
+	}
+	return it.forward()
+}
+
+// Last moves to the largest key, returning false if the trie is empty.
+func (it *Iterator[V]) Last() bool {
+	if !it.reset() {
+		return false
+	}
+	it.descendLast()
+	return it.backward()
+}
+
+// Next moves to the following key, returning false if there is none.
+func (it *Iterator[V]) Next() bool {
+	if !it.Valid() || !it.stepNext() {
+		return false
+	}
+	return it.forward()
+}
+
+// Prev moves to the preceding key, returning false if there is none.
+func (it *Iterator[V]) Prev() bool {
+	if !it.Valid() || !it.stepPrev() {
+		return false
+	}
+	return it.backward()
+}
+
+// SeekGE moves to the smallest key greater than or equal to k, returning
+// false if there is none.
+func (it *Iterator[V]) SeekGE(k []byte) bool {
+	if !it.reset() {
+		return false
+	}
+	for depth := 0; ; {
+		n := it.node()
+		d, short := n.key.commonBytesLen(k, depth)
+		switch {
+		case d == len(k):
+			// k is a prefix of n.key, so n and everything under it is >= k.
+			return it.forward()
+		case short:
+			if n.key[d] > k[d] {
+				return it.forward()
+			}
+			// Everything under n is < k.
+			return it.skip() && it.forward()
+		}
+		depth = d
+
+		i, child := n.edges.get(k[depth], depth)
+		switch {
+		case child != nil:
+			it.push(child, i)
+		case i < len(n.edges):
+			it.push(n.edges[i], i)
+			return it.forward()
+		default:
+			return it.skip() && it.forward()
+		}
+	}
+}
+
+// SeekLE moves to the largest key less than or equal to k, returning false if
+// there is none.
+func (it *Iterator[V]) SeekLE(k []byte) bool {
+	if !it.reset() {
+		return false
+	}
+	for depth := 0; ; {
+		n := it.node()
+		d, short := n.key.commonBytesLen(k, depth)
+		switch {
+		case d == len(k):
+			if len(n.key) == len(k) {
+				return it.backward()
+			}
+			// n.key is longer than k, so n and everything under it is > k.
+			return it.stepPrev() && it.backward()
+		case short:
+			if n.key[d] > k[d] {
+				return it.stepPrev() && it.backward()
+			}
+			// Everything under n is < k.
+			it.descendLast()
+			return it.backward()
+		}
+		depth = d
+
+		i, child := n.edges.get(k[depth], depth)
+		switch {
+		case child != nil:
+			it.push(child, i)
+		case i > 0:
+			it.push(n.edges[i-1], i-1)
+			it.descendLast()
+			return it.backward()
+		default:
+			return it.backward()
+		}
+	}
+}
+
+// SeekGEString is like SeekGE, but takes a string key.
+func (it *Iterator[V]) SeekGEString(k string) bool {
+	return it.SeekGE([]byte(k))
+}
+
+// SeekLEString is like SeekLE, but takes a string key.
+func (it *Iterator[V]) SeekLEString(k string) bool {
+	return it.SeekLE([]byte(k))
+}
+
+func (it *Iterator[V]) node() *Node[V] {
+	if n := len(it.stack); n > 0 {
+		return it.stack[n-1].node
+	}
+	return nil
+}
+
+func (it *Iterator[V]) push(n *Node[V], i int) {
+	it.stack = append(it.stack, iterFrame[V]{node: n, index: i})
+}
+
+// reset positions the iterator at the root, returning false if there is none.
+func (it *Iterator[V]) reset() bool {
+	it.stack = it.stack[:0]
+	if it.root == nil {
+		return false
+	}
+	it.push(it.root, 0)
+	return true
+}
+
+// forward moves forward until it reaches a node with a value.
+func (it *Iterator[V]) forward() bool {
+	for it.Valid() {
+		if it.node().hasValue() {
+			return true
+		}
+		it.stepNext()
+	}
+	return false
+}
+
+// backward moves backward until it reaches a node with a value.
+func (it *Iterator[V]) backward() bool {
+	for it.Valid() {
+		if it.node().hasValue() {
+			return true
+		}
+		it.stepPrev()
+	}
+	return false
+}
+
+// stepNext moves to the next node in key order, with or without a value.
+func (it *Iterator[V]) stepNext() bool {
+	if n := it.node(); len(n.edges) > 0 {
+		it.push(n.edges[0], 0)
+		return true
+	}
+	return it.skip()
+}
+
+// skip moves past the current node and everything under it.
+func (it *Iterator[V]) skip() bool {
+	for len(it.stack) > 1 {
+		top := it.stack[len(it.stack)-1]
+		it.stack = it.stack[:len(it.stack)-1]
+
+		if i := top.index + 1; i < len(it.node().edges) {
+			it.push(it.node().edges[i], i)
+			return true
+		}
+	}
+	it.stack = it.stack[:0]
+	return false
+}
+
+// stepPrev moves to the previous node in key order, with or without a value.
+func (it *Iterator[V]) stepPrev() bool {
+	if len(it.stack) < 2 {
+		it.stack = it.stack[:0]
+		return false
+	}
+	top := it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+
+	if i := top.index - 1; i >= 0 {
+		it.push(it.node().edges[i], i)
+		it.descendLast()
+	}
+	return true
+}
+
+// descendLast moves to the last node under the current one.
+func (it *Iterator[V]) descendLast() {
+	for n := it.node(); len(n.edges) > 0; n = it.node() {
+		i := len(n.edges) - 1
+		it.push(n.edges[i], i)
+	}
+}
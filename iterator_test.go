@@ -0,0 +1,114 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+var iterKeys = []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "c", "foo", "foo bar", "foo baz", "food"}
+
+func buildIterTrie(keys []string) *Node[int] {
+	tx := new(Txn[int])
+	for i, k := range keys {
+		tx.PutString(k, i+1)
+	}
+	return tx.Commit()
+}
+
+func TestIteratorForward(t *testing.T) {
+	it := buildIterTrie(iterKeys).Iterator()
+
+	var got []string
+	for ok := it.First(); ok; ok = it.Next() {
+		got = append(got, string(it.Key()))
+	}
+	if !equalStrings(got, iterKeys) {
+		t.Errorf("expected %q, got %q", iterKeys, got)
+	}
+}
+
+func TestIteratorBackward(t *testing.T) {
+	it := buildIterTrie(iterKeys).Iterator()
+
+	var got []string
+	for ok := it.Last(); ok; ok = it.Prev() {
+		got = append(got, string(it.Key()))
+	}
+	want := make([]string, len(iterKeys))
+	for i, k := range iterKeys {
+		want[len(want)-1-i] = k
+	}
+	if !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestIteratorSeek(t *testing.T) {
+	// Leave out "" and "ab" so seeks land between keys and on valueless nodes.
+	var keys []string
+	for _, k := range iterKeys {
+		if k != "" && k != "ab" {
+			keys = append(keys, k)
+		}
+	}
+	it := buildIterTrie(keys).Iterator()
+
+	probes := []string{"", "0", "a", "aa", "ab", "abb", "abz", "az", "b", "bb", "d", "fo", "foo b", "foo bb", "fooz", "z"}
+	for _, p := range probes {
+		i := sort.SearchStrings(keys, p)
+		if ok := it.SeekGEString(p); ok != (i < len(keys)) {
+			t.Errorf("SeekGE(%q): expected %v, got %v", p, i < len(keys), ok)
+		} else if ok && string(it.Key()) != keys[i] {
+			t.Errorf("SeekGE(%q): expected %q, got %q", p, keys[i], it.Key())
+		}
+
+		j := sort.Search(len(keys), func(i int) bool { return keys[i] > p }) - 1
+		if ok := it.SeekLEString(p); ok != (j >= 0) {
+			t.Errorf("SeekLE(%q): expected %v, got %v", p, j >= 0, ok)
+		} else if ok && string(it.Key()) != keys[j] {
+			t.Errorf("SeekLE(%q): expected %q, got %q", p, keys[j], it.Key())
+		}
+	}
+}
+
+func TestIteratorSeekThenStep(t *testing.T) {
+	it := buildIterTrie(iterKeys).Iterator()
+
+	if !it.SeekGEString("abz") || !bytes.Equal(it.Key(), []byte("b")) {
+		t.Fatalf(`expected SeekGE("abz") to find "b", got %q`, it.Key())
+	}
+	if !it.Prev() || !bytes.Equal(it.Key(), []byte("abd")) {
+		t.Errorf(`expected Prev to find "abd", got %q`, it.Key())
+	}
+	if !it.Next() || !it.Next() || !bytes.Equal(it.Key(), []byte("ba")) {
+		t.Errorf(`expected Next twice to find "ba", got %q`, it.Key())
+	}
+	if v := it.Value(); v != 7 {
+		t.Errorf(`expected "ba" to be 7, got %d`, v)
+	}
+}
+
+func TestIteratorEmpty(t *testing.T) {
+	var n *Node[int]
+	it := n.Iterator()
+
+	if it.First() || it.Last() || it.SeekGE(foo) || it.SeekLE(foo) || it.Valid() {
+		t.Errorf("expected an empty iterator to never be valid")
+	}
+	if it.Key() != nil || it.Value() != 0 {
+		t.Errorf("expected an empty iterator to return zero values")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
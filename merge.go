@@ -1,8 +1,11 @@
 package trie
 
-import "reflect"
+// Resolver picks the value for a key held by both sides of a merge: a is the
+// value already in the trie and b the one being merged in. Returning false
+// deletes the key.
+type Resolver[V any] func(key []byte, a, b V) (V, bool)
 
-func (a *Node) Merge(b *Node) (n *Node) {
+func (a *Node[V]) Merge(b *Node[V]) (n *Node[V]) {
 	if a == nil {
 		return b
 	}
@@ -13,6 +16,14 @@ func (a *Node) Merge(b *Node) (n *Node) {
 	return
 }
 
+// MergeFunc is like Merge, but calls fn to pick the value for every key held
+// by both a and b, rather than always taking the one from b.
+func (a *Node[V]) MergeFunc(b *Node[V], fn Resolver[V]) *Node[V] {
+	t := &Txn[V]{root: a}
+	t.MergeFunc(b, fn)
+	return t.root
+}
+
 type mergeSide uint8
 
 const (
@@ -22,7 +33,7 @@ const (
 	mergeUseE                  // no changes were needed to either side
 )
 
-func mergeEdges(t *Txn, depth int, a, b edges, reverse bool) (edges, mergeSide) {
+func mergeEdges[V any](t *Txn[V], depth int, a, b edges[V], reverse bool) (edges[V], mergeSide) {
 	if len(b) == 0 {
 		if len(a) == 0 {
 			return nil, mergeUseE
@@ -36,7 +47,7 @@ func mergeEdges(t *Txn, depth int, a, b edges, reverse bool) (edges, mergeSide)
 	var (
 		i     int
 		count int
-		nodes = new([256]*Node)
+		nodes = new([256]*Node[V])
 		side  = mergeUseE
 	)
 
@@ -95,7 +106,7 @@ top:
 		side &= ^mergeUseA
 	}
 
-	es := make(edges, 0, count)
+	es := make(edges[V], 0, count)
 	for _, n := range nodes {
 		if n == nil {
 			continue
@@ -105,7 +116,7 @@ top:
 	return es, side
 }
 
-func mergeNodes(t *Txn, depth int, a, b *Node, reverse bool) (*Node, mergeSide) {
+func mergeNodes[V any](t *Txn[V], depth int, a, b *Node[V], reverse bool) (*Node[V], mergeSide) {
 	d, short := a.key.commonBytesLen(b.key, depth)
 	if short {
 		// one key is a prefix of the other
@@ -117,13 +128,14 @@ func mergeNodes(t *Txn, depth int, a, b *Node, reverse bool) (*Node, mergeSide)
 		// the keys don't match
 		es, modified := a.edges.add(t, d, b, reverse)
 		if !modified {
-			return a, mergeUseA
+			return t.reuse(a), mergeUseA
 		}
 		if t.isMutable(a) {
 			a.edges = es
+			a.resize()
 			return a, mergeUseA
 		}
-		return t.newNode(a.key, a.value, es), mergeNewC
+		return t.newNode(a.key, a.value, a.present, es), mergeNewC
 	}
 	if debugEnabled && d > len(b.key) {
 		panic("merge: sanity check failed; d > len(b.key)")
@@ -132,22 +144,23 @@ func mergeNodes(t *Txn, depth int, a, b *Node, reverse bool) (*Node, mergeSide)
 	// both keys match exactly
 	// time to pick the value and merge the edges
 
-	v, vSide := mergeValues(a.value, b.value, reverse)
+	v, vSide := mergeValues(t, a, b, reverse)
 	e, eSide := mergeEdges(t, d, a.edges, b.edges, reverse)
+	has := a.present || b.present
 
 	side := resolveSide(vSide, eSide)
 	switch side {
 	case mergeUseE:
 		if reverse {
-			return a, side
+			return t.reuse(a), side
 		}
-		return b, side
+		return t.reuse(b), side
 	case mergeUseB:
 		debugf("merge: reusing B")
-		return b, side
+		return t.reuse(b), side
 	case mergeUseA:
 		debugf("merge: reusing A")
-		return a, side
+		return t.reuse(a), side
 	default:
 		panic("merge: invalid side")
 	case mergeNewC:
@@ -156,31 +169,41 @@ func mergeNodes(t *Txn, depth int, a, b *Node, reverse bool) (*Node, mergeSide)
 	switch {
 	case t.isMutable(a):
 		debugf("merge: mutating A")
-		a.value = v
+		a.value, a.present = v, has
 		a.edges = e
+		a.resize()
 		return a, mergeUseA // FIXME: Is this correct?
 	case t.isMutable(b):
 		debugf("merge: mutating B")
-		b.value = v
+		b.value, b.present = v, has
 		b.edges = e
+		b.resize()
 		return b, mergeUseB // FIXME: Is this correct?
 	}
 	debugf("merge: creating a new node")
-	return t.newNode(a.key, v, e), side
+	return t.newNode(a.key, v, has, e), side
 }
 
-func mergeValues(a, b interface{}, reverse bool) (interface{}, mergeSide) {
+// mergeValues picks the value for two nodes with the same key.
+func mergeValues[V any](t *Txn[V], x, y *Node[V], reverse bool) (V, mergeSide) {
+	a, b := x.value, y.value
 	switch {
-	case b == nil:
-		if a == nil {
-			return nil, mergeUseE // Both are nil, no preference.
+	case !y.present:
+		if !x.present {
+			return a, mergeUseE // Both are empty, no preference.
 		}
-		// Always take the non-nil value.
+		// Always take the non-empty value.
 		return a, mergeUseA
-	case a == nil:
-		// Always take the non-nil value.
+	case !x.present:
+		// Always take the non-empty value.
 		return b, mergeUseB
-	case reflect.DeepEqual(a, b):
+	case t.resolving():
+		// Let the resolver pick, passing the transaction's side first.
+		if reverse {
+			return resolveValue(t, x.key, b, a, reverse)
+		}
+		return resolveValue(t, x.key, a, b, reverse)
+	case t.equal(a, b):
 		// Prefer A over B if they are the same.
 		// This only matters if the caller creates a new node.
 		return a, mergeUseE
@@ -193,6 +216,28 @@ func mergeValues(a, b interface{}, reverse bool) (interface{}, mergeSide) {
 	}
 }
 
+// resolveValue asks the transaction's resolver for the value under k, where x
+// is the value in the transaction and y the one being merged in.
+func resolveValue[V any](t *Txn[V], k Key, x, y V, reverse bool) (V, mergeSide) {
+	v, keep := t.resolve(k, x, y)
+	if !keep {
+		t.dropped = append(t.dropped, k)
+	}
+	a, b := x, y
+	if reverse {
+		a, b = y, x
+	}
+	switch eqA, eqB := t.equal(v, a), t.equal(v, b); {
+	case eqA && eqB:
+		return a, mergeUseE
+	case eqA:
+		return a, mergeUseA
+	case eqB:
+		return b, mergeUseB
+	}
+	return v, mergeNewC
+}
+
 func resolveSide(value mergeSide, edges mergeSide) mergeSide {
 	if value == edges {
 		return value
@@ -207,20 +252,22 @@ func resolveSide(value mergeSide, edges mergeSide) mergeSide {
 }
 
 // split returns a (possibly new) Node with A and/or B as edges.
-func split(t *Txn, depth int, a, b *Node, reverse bool) (*Node, mergeSide) {
+func split[V any](t *Txn[V], depth int, a, b *Node[V], reverse bool) (*Node[V], mergeSide) {
 	if len(b.key) == depth {
 		es, modified := b.edges.add(t, depth, a, !reverse)
 		if !modified {
-			return b, mergeUseB
+			return t.reuse(b), mergeUseB
 		}
-		if t.isMutable(a) {
+		if t.isMutable(b) {
 			b.edges = es
+			b.resize()
 			return b, mergeUseB
 		}
-		return t.newNode(b.key, b.value, es), mergeNewC
+		return t.newNode(b.key, b.value, b.present, es), mergeNewC
 	}
 	if b.key[depth] < a.key[depth] {
 		a, b = b, a
 	}
-	return t.newNode(a.key[:depth], nil, edges{a, b}), mergeNewC
+	var zero V
+	return t.newNode(a.key[:depth], zero, false, edges[V]{a, b}), mergeNewC
 }
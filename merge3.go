@@ -0,0 +1,70 @@
+package trie
+
+// Conflict is a key that both sides of a three-way merge changed, to
+// different values. The Has fields say whether each side held the key at all,
+// telling a deletion apart from a stored zero value.
+type Conflict[V any] struct {
+	Key                 []byte
+	Base                V
+	A, B                V
+	HasBase, HasA, HasB bool
+}
+
+// ConflictFunc resolves a conflict. It returns ok false to leave the conflict
+// unresolved; otherwise the key is set to v if keep is true, or deleted.
+type ConflictFunc[V any] func(c Conflict[V]) (v V, keep, ok bool)
+
+// Merge3 merges the changes made to base by b into a, which was also derived
+// from base. A key changed by only one side takes that side's value, which
+// includes deletions. A key changed by both sides to different values is
+// passed to resolve, if not nil; conflicts it does not resolve keep the value
+// from a and are returned.
+//
+// Only the parts of b that differ from base are visited, so tries sharing
+// most of their nodes with base merge in time proportional to the change.
+// Like Node.Put, it leaves the watch channels of a alone.
+func Merge3[V any](base, a, b *Node[V], resolve ConflictFunc[V]) (*Node[V], []Conflict[V]) {
+	switch {
+	case b == base:
+		return a, nil
+	case a == base:
+		return b, nil
+	}
+
+	var conflicts []Conflict[V]
+	t := a.Txn()
+	diffNodes(base, b, nil, func(k []byte, x, y *Node[V]) {
+		c := Conflict[V]{Key: k}
+		if x != nil {
+			c.Base, c.HasBase = x.value, true
+		}
+		if y != nil {
+			c.B, c.HasB = y.value, true
+		}
+		c.A, c.HasA = a.Lookup(k)
+
+		v, keep := c.B, c.HasB
+		switch {
+		case c.HasA == c.HasBase && (!c.HasA || t.equal(c.A, c.Base)):
+			// only b changed it
+		case c.HasA == c.HasB && (!c.HasA || t.equal(c.A, c.B)):
+			// both made the same change
+			return
+		default:
+			var ok bool
+			if resolve != nil {
+				v, keep, ok = resolve(c)
+			}
+			if !ok {
+				conflicts = append(conflicts, c)
+				return
+			}
+		}
+		if keep {
+			t.Put(k, v)
+		} else {
+			t.Delete(k)
+		}
+	})
+	return t.commit(), conflicts
+}
@@ -0,0 +1,108 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := buildIterTrie([]string{"a", "b", "c", "d", "e", "f"}) // 1 through 6
+
+	tx := base.Txn()
+	tx.PutString("a", 10) // only a
+	tx.DeleteString("b")  // only a
+	tx.PutString("d", 40) // both, same
+	tx.PutString("e", 50) // both, differently
+	tx.DeleteString("f")  // a deletes, b changes
+	a := tx.Commit()
+
+	tx = base.Txn()
+	tx.PutString("c", 30) // only b
+	tx.DeleteString("c2") // nothing
+	tx.PutString("d", 40) // both, same
+	tx.PutString("e", 51) // both, differently
+	tx.PutString("f", 60) // a deletes, b changes
+	tx.PutString("g", 70) // only b
+	b := tx.Commit()
+
+	m, conflicts := Merge3(base, a, b, nil)
+
+	want := map[string]int{"a": 10, "c": 30, "d": 40, "e": 50, "g": 70}
+	for k, v := range want {
+		if got := m.GetString(k); got != v {
+			t.Errorf("expected %q to be %d, got %d", k, v, got)
+		}
+	}
+	if m.Len() != len(want) {
+		t.Errorf("expected %d values, got %d", len(want), m.Len())
+	}
+
+	got := fmt.Sprint(conflicts)
+	if want := "[{[101] 5 50 51 true true true} {[102] 6 0 60 true false true}]"; got != want {
+		t.Errorf("expected conflicts %s, got %s", want, got)
+	}
+}
+
+func TestMerge3Resolve(t *testing.T) {
+	base := buildIterTrie([]string{"a", "b"})
+	a := base.PutString("a", 10).PutString("b", 20)
+	b := base.PutString("a", 11).PutString("b", 21)
+
+	m, conflicts := Merge3(base, a, b, func(c Conflict[int]) (int, bool, bool) {
+		switch string(c.Key) {
+		case "a":
+			return c.A + c.B - c.Base, true, true
+		case "b":
+			return 0, false, true
+		}
+		return 0, false, false
+	})
+	if len(conflicts) != 0 {
+		t.Errorf("expected every conflict to be resolved, got %v", conflicts)
+	}
+	if v := m.GetString("a"); v != 20 || m.Len() != 1 {
+		t.Errorf(`expected only "a" = 20, got %#v`, m)
+	}
+}
+
+func TestMerge3Shortcuts(t *testing.T) {
+	base := buildIterTrie(iterKeys)
+	a := base.PutString("abc", 40)
+
+	if m, _ := Merge3(base, a, base, nil); m != a {
+		t.Errorf("expected an unchanged b to return a")
+	}
+	if m, _ := Merge3(base, base, a, nil); m != a {
+		t.Errorf("expected an unchanged a to return b")
+	}
+}
+
+func TestMerge3ZeroValues(t *testing.T) {
+	base := buildIterTrie([]string{"a", "b"})
+	a := base.PutString("a", 0).PutString("b", 0)
+	b := base.DeleteString("a").PutString("b", 0)
+
+	m, conflicts := Merge3(base, a, b, nil)
+	if len(conflicts) != 1 || string(conflicts[0].Key) != "a" || !conflicts[0].HasA || conflicts[0].HasB {
+		t.Errorf(`expected storing and deleting "a" to conflict, got %v`, conflicts)
+	}
+	if v, ok := m.LookupString("b"); v != 0 || !ok {
+		t.Errorf(`expected "b" to hold 0, got %d, %v`, v, ok)
+	}
+}
+
+func TestMerge3KeepsWatches(t *testing.T) {
+	base := buildIterTrie([]string{"a", "b"})
+	a := base.PutString("a", 10)
+	b := base.PutString("b", 20)
+
+	_, prefixCh := a.PrefixWatchString("")
+	_, keyCh := a.GetWatchString("b")
+
+	if m, _ := Merge3(base, a, b, nil); m.GetString("a") != 10 || m.GetString("b") != 20 {
+		t.Fatalf("expected both changes to be merged, got %#v", m)
+	}
+	if isClosed(prefixCh) || isClosed(keyCh) {
+		t.Errorf("expected Merge3 to leave the watches on a open")
+	}
+}
@@ -16,11 +16,11 @@ import (
 // }
 
 func TestMergeNodes_nilValue(t *testing.T) {
-	n := node("food", 1, edges{
+	n := node("food", 1, anyEdges{
 		node("foodz", 2, nil),
 	})
-	o := node("foo", 1, edges{
-		node("food", nil, edges{
+	o := node("foo", 1, anyEdges{
+		node("food", nil, anyEdges{
 			node("foods", 2, nil),
 			node("foodz", 3, nil),
 		}),
@@ -28,15 +28,15 @@ func TestMergeNodes_nilValue(t *testing.T) {
 	p := n.Merge(o)
 	q := o.Merge(n)
 
-	expectedP := node("foo", 1, edges{
-		node("food", 1, edges{
+	expectedP := node("foo", 1, anyEdges{
+		node("food", 1, anyEdges{
 			node("foods", 2, nil),
 			node("foodz", 3, nil),
 		}),
 	})
 
-	expectedQ := node("foo", 1, edges{
-		node("food", 1, edges{
+	expectedQ := node("foo", 1, anyEdges{
+		node("food", 1, anyEdges{
 			node("foods", 2, nil),
 			node("foodz", 2, nil),
 		}),
@@ -59,20 +59,20 @@ func TestMergeNodes(t *testing.T) {
 		t.Errorf("expected %q.merge(%q) to result in %p, got %p", o.key, n.key, n, res)
 	}
 
-	n = node("food", 1, edges{
-		node("foodi", nil, edges{
-			node("foodie", 2, edges{
+	n = node("food", 1, anyEdges{
+		node("foodi", nil, anyEdges{
+			node("foodie", 2, anyEdges{
 				node("foodies", 3, nil),
 			}),
 			node("fooding", 4, nil),
 		}),
 		node("foodz", 5, nil),
 	})
-	o = node("foo", 1, edges{
+	o = node("foo", 1, anyEdges{
 		node("foo bar", 2, nil),
-		node("food", nil, edges{
+		node("food", nil, anyEdges{
 			node("fooding", 3, nil),
-			node("foods", 4, edges{
+			node("foods", 4, anyEdges{
 				node("foods with friends", 5, nil),
 			}),
 		}),
@@ -81,32 +81,32 @@ func TestMergeNodes(t *testing.T) {
 	p := n.Merge(o)
 	q := o.Merge(n)
 
-	expectedP := node("foo", 1, edges{
+	expectedP := node("foo", 1, anyEdges{
 		node("foo bar", 2, nil),
-		node("food", 1, edges{
-			node("foodi", nil, edges{
-				node("foodie", 2, edges{
+		node("food", 1, anyEdges{
+			node("foodi", nil, anyEdges{
+				node("foodie", 2, anyEdges{
 					node("foodies", 3, nil),
 				}),
 				node("fooding", 3, nil),
 			}),
-			node("foods", 4, edges{
+			node("foods", 4, anyEdges{
 				node("foods with friends", 5, nil),
 			}),
 			node("foodz", 5, nil),
 		}),
 	})
 
-	expectedQ := node("foo", 1, edges{
+	expectedQ := node("foo", 1, anyEdges{
 		node("foo bar", 2, nil),
-		node("food", 1, edges{
-			node("foodi", nil, edges{
-				node("foodie", 2, edges{
+		node("food", 1, anyEdges{
+			node("foodi", nil, anyEdges{
+				node("foodie", 2, anyEdges{
 					node("foodies", 3, nil),
 				}),
 				node("fooding", 4, nil),
 			}),
-			node("foods", 4, edges{
+			node("foods", 4, anyEdges{
 				node("foods with friends", 5, nil),
 			}),
 			node("foodz", 5, nil),
@@ -123,10 +123,10 @@ func TestMergeNodes(t *testing.T) {
 }
 
 func TestMerge_1(t *testing.T) {
-	n := &Node{key: fooBar, value: []byte("12345")}
+	n := node("foo bar", []byte("12345"), nil)
 	m := n.Put(fooBaz, []byte("2"))
 
-	tx := new(Txn)
+	tx := new(AnyTxn)
 	tx.Put(fooBar, []byte("12345"))
 	tx.Put(fooBaz, []byte("2"))
 	tx.Merge(m)
@@ -164,3 +164,105 @@ func TestMerge_3(t *testing.T) {
 		t.Fatalf(`expected "2", got %q`, res)
 	}
 }
+
+func TestTxnMergeLeavesOther(t *testing.T) {
+	other := (*Node[int])(nil).PutString("foo", 2)
+
+	tx := new(Txn[int])
+	tx.PutString("foobar", 1)
+	tx.Merge(other)
+	m := tx.Commit()
+
+	if m.Len() != 2 || m.GetString("foobar") != 1 || m.GetString("foo") != 2 {
+		t.Errorf("expected both keys in the merge, got %#v", m)
+	}
+	if _, ok := other.LookupString("foobar"); ok || other.Len() != 1 {
+		t.Errorf("expected the merged in trie to be unchanged, got %#v", other)
+	}
+	checkSizes(t, other)
+}
+
+func TestMergeFunc(t *testing.T) {
+	a := buildIterTrie([]string{"a", "ab", "b", "c"})        // 1 2 3 4
+	b := buildIterTrie([]string{"ab", "abc", "b", "c", "d"}) // 1 2 3 4 5
+
+	var calls []string
+	sum := func(k []byte, x, y int) (int, bool) {
+		calls = append(calls, string(k))
+		return x + y, true
+	}
+
+	m := a.MergeFunc(b, sum)
+	want := map[string]int{"a": 1, "ab": 3, "abc": 2, "b": 6, "c": 8, "d": 5}
+	for k, v := range want {
+		if got := m.GetString(k); got != v {
+			t.Errorf("expected %q to be %d, got %d", k, v, got)
+		}
+	}
+	if m.Len() != len(want) {
+		t.Errorf("expected %d values, got %d", len(want), m.Len())
+	}
+	if want := []string{"ab", "b", "c"}; !equalStrings(calls, want) {
+		t.Errorf("expected the resolver to be called for %q, got %q", want, calls)
+	}
+	if v := a.GetString("b"); v != 3 {
+		t.Errorf("expected the original trie to be unchanged, got %d", v)
+	}
+}
+
+func TestMergeFuncReverseSides(t *testing.T) {
+	a := (*Node[string])(nil).PutString("foobar", "a")
+	b := (*Node[string])(nil).PutString("foo", "x").PutString("foobar", "b")
+
+	// b has the shorter key, so the merge has to flip sides internally.
+	m := a.MergeFunc(b, func(k []byte, x, y string) (string, bool) {
+		return x + y, true
+	})
+	if v := m.GetString("foobar"); v != "ab" {
+		t.Errorf(`expected "ab", got %q`, v)
+	}
+}
+
+func TestMergeFuncDelete(t *testing.T) {
+	a := buildIterTrie([]string{"foo", "foobar", "fob"})
+	b := buildIterTrie([]string{"foo", "foobar", "fob"})
+
+	m := a.MergeFunc(b, func(k []byte, x, y int) (int, bool) {
+		return x, string(k) == "fob"
+	})
+	if m.Len() != 1 || m.GetString("fob") != 3 {
+		t.Errorf(`expected only "fob" to remain, got %#v`, m)
+	}
+	if m != a.PrefixString("fob") && m != b.PrefixString("fob") {
+		t.Errorf(`expected "fob" to be reused`)
+	}
+	checkSizes(t, m)
+
+	if m := a.MergeFunc(b, func([]byte, int, int) (int, bool) { return 0, false }); m != nil {
+		t.Errorf("expected deleting everything to leave an empty trie, got %#v", m)
+	}
+}
+
+func TestMergeFuncReuse(t *testing.T) {
+	a := buildIterTrie(iterKeys)
+	b := a.PutString("abc", 40)
+
+	keepA := func(_ []byte, x, _ int) (int, bool) { return x, true }
+	if m := a.MergeFunc(b, keepA); m != a {
+		t.Errorf("expected keeping every value from a to return a")
+	}
+}
+
+func TestTxnMergeFuncChanges(t *testing.T) {
+	tx := buildIterTrie([]string{"a", "b"}).Txn()
+	tx.TrackChanges()
+	tx.MergeFunc(buildIterTrie([]string{"b", "c"}), func(_ []byte, x, y int) (int, bool) {
+		return 0, false
+	})
+	tx.Commit()
+
+	cs := tx.Changes()
+	if len(cs) != 2 || string(cs[0].Key) != "b" || cs[0].Kind != Deleted || string(cs[1].Key) != "c" || cs[1].Kind != Inserted {
+		t.Errorf("expected b to be deleted and c inserted, got %v", cs)
+	}
+}
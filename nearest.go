@@ -0,0 +1,65 @@
+package trie
+
+// Min returns the smallest key and its value.
+func (n *Node[V]) Min() (key []byte, value V, ok bool) {
+	for n != nil {
+		if n.hasValue() {
+			return n.key, n.value, true
+		}
+		if len(n.edges) == 0 {
+			break
+		}
+		n = n.edges[0]
+	}
+	return
+}
+
+// Max returns the largest key and its value.
+func (n *Node[V]) Max() (key []byte, value V, ok bool) {
+	for n != nil {
+		if len(n.edges) == 0 {
+			if n.hasValue() {
+				return n.key, n.value, true
+			}
+			break
+		}
+		n = n.edges[len(n.edges)-1]
+	}
+	return
+}
+
+// Ceiling returns the smallest key greater than or equal to k, and its value.
+func (n *Node[V]) Ceiling(k []byte) (key []byte, value V, ok bool) {
+	it := n.Iterator()
+	if it.SeekGE(k) {
+		return it.Key(), it.Value(), true
+	}
+	return
+}
+
+// Floor returns the largest key less than or equal to k, and its value.
+func (n *Node[V]) Floor(k []byte) (key []byte, value V, ok bool) {
+	it := n.Iterator()
+	if it.SeekLE(k) {
+		return it.Key(), it.Value(), true
+	}
+	return
+}
+
+// Higher returns the smallest key strictly greater than k, and its value.
+func (n *Node[V]) Higher(k []byte) (key []byte, value V, ok bool) {
+	it := n.Iterator()
+	if it.seekBound(Exclusive(k)) {
+		return it.Key(), it.Value(), true
+	}
+	return
+}
+
+// Lower returns the largest key strictly less than k, and its value.
+func (n *Node[V]) Lower(k []byte) (key []byte, value V, ok bool) {
+	it := n.Iterator()
+	if it.seekBoundReverse(Exclusive(k)) {
+		return it.Key(), it.Value(), true
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package trie
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestMinMax(t *testing.T) {
+	n := buildIterTrie(iterKeys[1:])
+
+	if k, v, ok := n.Min(); !ok || string(k) != "a" || v != 1 {
+		t.Errorf(`expected Min to be "a", got %q`, k)
+	}
+	if k, v, ok := n.Max(); !ok || string(k) != "food" || v != len(iterKeys)-1 {
+		t.Errorf(`expected Max to be "food", got %q`, k)
+	}
+
+	var empty *Node[int]
+	if _, _, ok := empty.Min(); ok {
+		t.Errorf("expected Min of an empty trie to fail")
+	}
+	if _, _, ok := empty.Max(); ok {
+		t.Errorf("expected Max of an empty trie to fail")
+	}
+}
+
+func TestNearest(t *testing.T) {
+	keys := iterKeys[1:]
+	n := buildIterTrie(keys)
+
+	type lookup func([]byte) ([]byte, int, bool)
+	check := func(name string, fn lookup, p string, i int) {
+		k, v, ok := fn([]byte(p))
+		if i < 0 || i >= len(keys) {
+			if ok {
+				t.Errorf("%s(%q): expected nothing, got %q", name, p, k)
+			}
+			return
+		}
+		if !ok || string(k) != keys[i] || v != i+1 {
+			t.Errorf("%s(%q): expected %q, got %q", name, p, keys[i], k)
+		}
+	}
+
+	for _, p := range []string{"", "a", "aa", "ab", "abc", "abz", "b", "bab", "bz", "foo", "foo bar", "fooa", "zz"} {
+		ge := sort.SearchStrings(keys, p)
+		gt := sort.Search(len(keys), func(i int) bool { return keys[i] > p })
+
+		check("Ceiling", n.Ceiling, p, ge)
+		check("Higher", n.Higher, p, gt)
+		check("Floor", n.Floor, p, gt-1)
+		check("Lower", n.Lower, p, ge-1)
+	}
+}
@@ -1,14 +1,43 @@
 package trie
 
-import "reflect"
+import "sync/atomic"
 
-type Node struct {
-	key   Key
-	value interface{}
-	edges edges
+// Node is an immutable trie node holding values of type V. Any value can be
+// stored, including nil and other zero values.
+type Node[V any] struct {
+	key     Key
+	value   V
+	present bool // whether the node holds a value of its own
+	edges   edges[V]
+	size    int                     // number of values in this subtree
+	aux     atomic.Pointer[nodeAux] // allocated on first use
 }
 
-func (n *Node) Get(k []byte) interface{} {
+// nodeAux holds the state of nodes that are watched, hashed or replaced by a
+// commit, so the rest only pay for a pointer.
+type nodeAux struct {
+	watch  atomic.Pointer[chan struct{}]
+	digest atomic.Pointer[hashEntry] // cached by Hasher.Sum
+}
+
+// getAux returns the node's own nodeAux, allocating it if needed.
+func (n *Node[V]) getAux() *nodeAux {
+	for {
+		a := n.aux.Load()
+		if a != nil && a != replacedAux {
+			return a
+		}
+		b := new(nodeAux)
+		if a == replacedAux {
+			b.watch.Store(&closedWatch)
+		}
+		if n.aux.CompareAndSwap(a, b) {
+			return b
+		}
+	}
+}
+
+func (n *Node[V]) Get(k []byte) V {
 	for i := 0; n != nil; {
 		end := len(n.key)
 
@@ -22,10 +51,11 @@ func (n *Node) Get(k []byte) interface{} {
 
 		_, n = n.edges.get(k[i], i)
 	}
-	return nil
+	var zero V
+	return zero
 }
 
-func (n *Node) GetString(k string) interface{} {
+func (n *Node[V]) GetString(k string) V {
 	for i := 0; n != nil; {
 		end := len(n.key)
 
@@ -39,38 +69,164 @@ func (n *Node) GetString(k string) interface{} {
 
 		_, n = n.edges.get(k[i], i)
 	}
+	var zero V
+	return zero
+}
+
+// Lookup returns the value for k and whether there is one, telling a stored
+// zero value apart from a missing key.
+func (n *Node[V]) Lookup(k []byte) (V, bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToBytes(k[i:end]) {
+			break
+		}
+		if len(k) == end {
+			return n.value, n.present
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+	var zero V
+	return zero, false
+}
+
+// LookupString is like Lookup, but takes a string key.
+func (n *Node[V]) LookupString(k string) (V, bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToString(k[i:end]) {
+			break
+		}
+		if len(k) == end {
+			return n.value, n.present
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+	var zero V
+	return zero, false
+}
+
+// Prefix returns the subtree holding every key that starts with p, or nil if
+// there are none. The result shares its nodes with n.
+func (n *Node[V]) Prefix(p []byte) *Node[V] {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(p) <= end {
+			if n.key[i:len(p)].EqualToBytes(p[i:]) {
+				return n
+			}
+			break
+		}
+		if !n.key[i:].EqualToBytes(p[i:end]) {
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(p[i], i)
+	}
 	return nil
 }
 
-func (n *Node) Delete(k []byte) *Node {
+// PrefixString is like Prefix, but takes a string prefix.
+func (n *Node[V]) PrefixString(p string) *Node[V] {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(p) <= end {
+			if n.key[i:len(p)].EqualToString(p[i:]) {
+				return n
+			}
+			break
+		}
+		if !n.key[i:].EqualToString(p[i:end]) {
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(p[i], i)
+	}
+	return nil
+}
+
+// LongestPrefix returns the longest key with a value that is a prefix of k,
+// along with its value.
+func (n *Node[V]) LongestPrefix(k []byte) (key []byte, value V, ok bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToBytes(k[i:end]) {
+			break
+		}
+		if n.hasValue() {
+			key, value, ok = n.key, n.value, true
+		}
+		if len(k) == end {
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+	return
+}
+
+// LongestPrefixString is like LongestPrefix, but takes a string key.
+func (n *Node[V]) LongestPrefixString(k string) (key []byte, value V, ok bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToString(k[i:end]) {
+			break
+		}
+		if n.hasValue() {
+			key, value, ok = n.key, n.value, true
+		}
+		if len(k) == end {
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+	return
+}
+
+func (n *Node[V]) Delete(k []byte) *Node[V] {
 	if n == nil {
 		return nil
 	}
-	return n.delete(&Txn{root: n}, 0, k)
+	return n.delete(&Txn[V]{root: n}, 0, k)
 }
 
-func (n *Node) DeleteString(k string) *Node {
+func (n *Node[V]) DeleteString(k string) *Node[V] {
 	if n == nil {
 		return nil
 	}
-	return n.deleteString(&Txn{root: n}, 0, k)
+	return n.deleteString(&Txn[V]{root: n}, 0, k)
 }
 
-func (n *Node) Put(k []byte, v interface{}) *Node {
+func (n *Node[V]) Put(k []byte, v V) *Node[V] {
 	if n == nil {
-		return &Node{key: k, value: v}
+		return (*Txn[V])(nil).newNode(k, v, true, nil)
 	}
-	return n.put(&Txn{root: n}, 0, k, v, nil)
+	return n.put(&Txn[V]{root: n}, 0, k, v, nil)
 }
 
-func (n *Node) PutString(k string, v interface{}) *Node {
+func (n *Node[V]) PutString(k string, v V) *Node[V] {
 	if n == nil {
-		return &Node{key: Key(k), value: v}
+		return (*Txn[V])(nil).newNode(Key(k), v, true, nil)
 	}
-	return n.putString(&Txn{root: n}, 0, k, v, nil)
+	return n.putString(&Txn[V]{root: n}, 0, k, v, nil)
 }
 
-func (n *Node) delete(t *Txn, depth int, k []byte) *Node {
+func (n *Node[V]) delete(t *Txn[V], depth int, k []byte) *Node[V] {
 	d, ok := n.key.commonBytesLen(k, depth)
 	if ok { // not found
 		return n
@@ -80,16 +236,24 @@ func (n *Node) delete(t *Txn, depth int, k []byte) *Node {
 	}
 	es, modified := n.edges.delete(t, d, k)
 	if !modified {
-		return n
+		return t.reuse(n)
+	}
+	if !n.hasValue() && len(es) < 2 {
+		// Nothing is left to keep this node around.
+		if len(es) == 0 {
+			return nil
+		}
+		return es[0]
 	}
 	if t.isMutable(n) {
 		n.edges = es
+		n.resize()
 		return n
 	}
-	return t.newNode(n.key, n.value, es)
+	return t.newNode(n.key, n.value, n.present, es)
 }
 
-func (n *Node) deleteString(t *Txn, depth int, k string) *Node {
+func (n *Node[V]) deleteString(t *Txn[V], depth int, k string) *Node[V] {
 	d, short := n.key.commonStringLen(k, depth)
 	if short { // not found
 		return n
@@ -99,16 +263,24 @@ func (n *Node) deleteString(t *Txn, depth int, k string) *Node {
 	}
 	es, modified := n.edges.deleteString(t, d, k)
 	if !modified {
-		return n
+		return t.reuse(n)
+	}
+	if !n.hasValue() && len(es) < 2 {
+		// Nothing is left to keep this node around.
+		if len(es) == 0 {
+			return nil
+		}
+		return es[0]
 	}
 	if t.isMutable(n) {
 		n.edges = es
+		n.resize()
 		return n
 	}
-	return t.newNode(n.key, n.value, es)
+	return t.newNode(n.key, n.value, n.present, es)
 }
 
-func (n *Node) deleteValue(t *Txn) *Node {
+func (n *Node[V]) deleteValue(t *Txn[V]) *Node[V] {
 	switch len(n.edges) {
 	case 0:
 		// t.maybeFree(n)
@@ -117,20 +289,42 @@ func (n *Node) deleteValue(t *Txn) *Node {
 		// t.maybeFree(n)
 		return n.edges[0]
 	}
-	if n.value != nil {
+	if n.hasValue() {
+		var zero V
 		if !t.isMutable(n) {
-			return t.newNode(n.key, nil, n.edges)
+			return t.newNode(n.key, zero, false, n.edges)
 		}
-		n.value = nil
+		n.value, n.present = zero, false
+		n.resize()
 	}
 	return n
 }
 
+// hasValue reports whether the node holds a value of its own.
+func (n *Node[V]) hasValue() bool {
+	return n.present
+}
+
+// resize recounts the values in the subtree after the node has changed, and
+// drops its cached digest.
+func (n *Node[V]) resize() {
+	if a := n.aux.Load(); a != nil && a != replacedAux {
+		a.digest.Store(nil)
+	}
+	n.size = 0
+	if n.hasValue() {
+		n.size = 1
+	}
+	for _, e := range n.edges {
+		n.size += e.size
+	}
+}
+
 // put sets the value (and merges the edges) under a given key.
-func (n *Node) put(t *Txn, depth int, k []byte, v interface{}, es edges) *Node {
+func (n *Node[V]) put(t *Txn[V], depth int, k []byte, v V, es edges[V]) *Node[V] {
 	d, short := n.key.commonBytesLen(k, depth)
 	if short { // split
-		n, _ = split(t, d, n, t.newNode(k, v, es), false)
+		n, _ = split(t, d, n, t.newNode(k, v, true, es), false)
 		return n
 	}
 	if d == len(k) { // exact match
@@ -138,20 +332,21 @@ func (n *Node) put(t *Txn, depth int, k []byte, v interface{}, es edges) *Node {
 	}
 	es, modified := n.edges.put(t, d, k, v, es)
 	if !modified {
-		return n
+		return t.reuse(n)
 	}
 	if t.isMutable(n) {
 		n.edges = es
+		n.resize()
 		return n
 	}
-	return t.newNode(n.key, n.value, es)
+	return t.newNode(n.key, n.value, n.present, es)
 }
 
 // putString is like put, but takes a string key.
-func (n *Node) putString(t *Txn, depth int, k string, v interface{}, es edges) *Node {
+func (n *Node[V]) putString(t *Txn[V], depth int, k string, v V, es edges[V]) *Node[V] {
 	d, short := n.key.commonStringLen(k, depth)
 	if short { // split
-		n, _ = split(t, d, n, t.newNode(Key(k), v, es), false)
+		n, _ = split(t, d, n, t.newNode(Key(k), v, true, es), false)
 		return n
 	}
 	if d == len(k) { // exact match
@@ -159,31 +354,33 @@ func (n *Node) putString(t *Txn, depth int, k string, v interface{}, es edges) *
 	}
 	es, modified := n.edges.putString(t, d, k, v, es)
 	if !modified {
-		return n
+		return t.reuse(n)
 	}
 	if t.isMutable(n) {
 		n.edges = es
+		n.resize()
 		return n
 	}
-	return t.newNode(n.key, n.value, es)
+	return t.newNode(n.key, n.value, n.present, es)
 }
 
 // set updates the value and merges in the provided edges.
-func (n *Node) set(t *Txn, v interface{}, e edges) *Node {
+func (n *Node[V]) set(t *Txn[V], v V, e edges[V]) *Node[V] {
 	e, side := mergeEdges(t, len(n.key), n.edges, e, false)
-	if side == mergeUseA {
-		if reflect.DeepEqual(n.value, v) {
+	if side == mergeUseA || side == mergeUseE {
+		if n.present && t.equal(n.value, v) {
 			debugf("set: nothing to change")
-			return n
+			return t.reuse(n)
 		}
 		debugf("set: no edges to add, but values not equal: %#v != %#v", n.value, v)
 	}
 	if t.isMutable(n) {
 		debugf("set: mutating")
-		n.value = v
+		n.value, n.present = v, true
 		n.edges = e
+		n.resize()
 		return n
 	}
 	debugf("set: creating a new node")
-	return t.newNode(n.key, v, e)
+	return t.newNode(n.key, v, true, e)
 }
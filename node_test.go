@@ -7,18 +7,20 @@ import (
 	"unsafe"
 )
 
-var emptyTrie *Node
+type anyEdges = edges[interface{}]
 
-var foodTrie = node("food", nil, edges{
-	node("foodie", 2, edges{
+var emptyTrie *AnyNode
+
+var foodTrie = node("food", nil, anyEdges{
+	node("foodie", 2, anyEdges{
 		node("foodies", 3, nil),
 	}),
 	node("foods", 4, nil),
 	node("foodz", 5, nil),
 })
 
-var footTrie = node("foot", nil, edges{
-	node("footie", 2, edges{
+var footTrie = node("foot", nil, anyEdges{
+	node("footie", 2, anyEdges{
 		node("footies", 3, nil),
 	}),
 	node("foots", 4, nil),
@@ -26,13 +28,13 @@ var footTrie = node("foot", nil, edges{
 })
 
 func TestSizeOfNode(t *testing.T) {
-	if size := unsafe.Sizeof(Node{}); size != 64 {
-		t.Errorf("expected Node to be 64 bytes, got %d", size)
+	if size := unsafe.Sizeof(AnyNode{}); size != 88 {
+		t.Errorf("expected Node to be 88 bytes, got %d", size)
 	}
 }
 
 func TestNodeGet(t *testing.T) {
-	testGet := func(n *Node, key string) interface{} {
+	testGet := func(n *AnyNode, key string) interface{} {
 		a := n.Get([]byte(key))
 		b := n.GetString(key)
 
@@ -53,7 +55,7 @@ func TestNodeGet(t *testing.T) {
 }
 
 func TestNodePut(t *testing.T) {
-	testPut := func(n *Node, key string, val interface{}) *Node {
+	testPut := func(n *AnyNode, key string, val interface{}) *AnyNode {
 		a := n.Put([]byte(key), val)
 		b := n.PutString(key, val)
 
@@ -99,7 +101,7 @@ func TestNodePut(t *testing.T) {
 }
 
 func TestNodeDelete(t *testing.T) {
-	testDelete := func(n *Node, key string) *Node {
+	testDelete := func(n *AnyNode, key string) *AnyNode {
 		a := n.Delete([]byte(key))
 		b := n.DeleteString(key)
 
@@ -139,19 +141,225 @@ func TestNodeDelete(t *testing.T) {
 	if res := m.GetString("foo baz").([]byte); !bytes.Equal(res, []byte("2")) {
 		t.Errorf(`expected "2", got %q`, res)
 	}
+	if !m.key.EqualToString("foo baz") {
+		t.Errorf(`expected the empty "foo ba" node to be removed, got %#v`, m)
+	}
+}
+
+func TestNodePrefix(t *testing.T) {
+	testPrefix := func(n *AnyNode, p string) *AnyNode {
+		a := n.Prefix([]byte(p))
+		b := n.PrefixString(p)
+
+		if a != b {
+			t.Errorf("Prefix does not agree with PrefixString for %q", p)
+		}
+
+		return a
+	}
+
+	if testPrefix(emptyTrie, "foo") != nil {
+		t.Errorf("expected Prefix of an empty trie to be nil")
+	}
+
+	if testPrefix(foodTrie, "fo") != foodTrie {
+		t.Errorf(`expected "fo" to return the whole trie`)
+	}
+
+	if testPrefix(foodTrie, "food") != foodTrie {
+		t.Errorf(`expected "food" to return the whole trie`)
+	}
+
+	if n := testPrefix(foodTrie, "foodi"); n != foodTrie.edges[0] {
+		t.Errorf(`expected "foodi" to return the "foodie" subtree, got %#v`, n)
+	}
+
+	if n := testPrefix(foodTrie, "foodies"); n != foodTrie.edges[0].edges[0] {
+		t.Errorf(`expected "foodies" to return the "foodies" node, got %#v`, n)
+	}
+
+	if testPrefix(foodTrie, "foodx") != nil || testPrefix(foodTrie, "fox") != nil || testPrefix(foodTrie, "foodiesx") != nil {
+		t.Errorf("expected Prefix to return nil for unknown prefixes")
+	}
+
+	m := foodTrie.PrefixString("foodie").PutString("foodie!", 6)
+	if v := m.GetString("foodies"); v != 3 {
+		t.Errorf(`expected the subtree to still hold "foodies", got %v`, v)
+	}
+	if foodTrie.GetString("foodie!") != nil {
+		t.Errorf("expected the original trie to be unchanged")
+	}
+}
+
+func TestNodeLongestPrefix(t *testing.T) {
+	n := node("foo", 1, anyEdges{
+		node("foo/", nil, anyEdges{
+			node("foo/bar", 2, anyEdges{
+				node("foo/bar/baz", 3, nil),
+			}),
+			node("foo/qux", 4, nil),
+		}),
+	})
+
+	table := []struct {
+		K     string
+		Key   string
+		Value interface{}
+		OK    bool
+	}{
+		{"f", "", nil, false},
+		{"foo", "foo", 1, true},
+		{"foo/", "foo", 1, true},
+		{"foo/ba", "foo", 1, true},
+		{"foo/bar", "foo/bar", 2, true},
+		{"foo/bar/", "foo/bar", 2, true},
+		{"foo/bar/baz/qux", "foo/bar/baz", 3, true},
+		{"foo/quux", "foo", 1, true},
+		{"fox", "", nil, false},
+	}
+	for _, x := range table {
+		key, value, ok := n.LongestPrefix([]byte(x.K))
+		skey, svalue, sok := n.LongestPrefixString(x.K)
+
+		if !bytes.Equal(key, skey) || value != svalue || ok != sok {
+			t.Errorf("LongestPrefix does not agree with LongestPrefixString for %q", x.K)
+		}
+		if string(key) != x.Key || value != x.Value || ok != x.OK {
+			t.Errorf("%q: expected (%q, %v, %v), got (%q, %v, %v)", x.K, x.Key, x.Value, x.OK, key, value, ok)
+		}
+	}
+}
+
+func TestNodeTyped(t *testing.T) {
+	var n *Node[int]
+	n = n.PutString("foo", 1).PutString("foobar", 2).PutString("fob", 3)
+
+	if v := n.GetString("foobar"); v != 2 {
+		t.Errorf(`expected "foobar" to be 2, got %d`, v)
+	}
+	if v := n.Get([]byte("fo")); v != 0 {
+		t.Errorf(`expected "fo" to be 0, got %d`, v)
+	}
+	if m := n.PutString("foo", 1); m != n {
+		t.Errorf("expected Put of an equal value to do nothing")
+	}
+	if m := n.DeleteString("foo"); m.GetString("foo") != 0 || m.GetString("foobar") != 2 {
+		t.Errorf("expected Delete to remove only %q: %#v", "foo", m)
+	}
+}
+
+func TestTxnComparator(t *testing.T) {
+	type item struct {
+		id   int
+		hits int
+	}
+	sameID := func(a, b *item) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return a.id == b.id
+	}
+
+	tx := new(Txn[*item])
+	tx.SetComparator(sameID)
+	tx.PutString("foo", &item{id: 1})
+	n := tx.Commit()
+
+	tx = &Txn[*item]{root: n}
+	tx.SetComparator(sameID)
+	tx.PutString("foo", &item{id: 1, hits: 5})
+
+	if m := tx.Commit(); m != n {
+		t.Errorf("expected the comparator to treat the values as equal")
+	}
+}
+
+func TestNodeZeroValues(t *testing.T) {
+	n := (*AnyNode)(nil).PutString("foo", nil).PutString("foobar", 1)
+
+	if v, ok := n.LookupString("foo"); v != nil || !ok {
+		t.Errorf(`expected "foo" to hold nil, got %v, %v`, v, ok)
+	}
+	if _, ok := n.Lookup([]byte("fo")); ok {
+		t.Errorf(`expected "fo" to be missing`)
+	}
+	if n.Len() != 2 {
+		t.Errorf("expected 2 values, got %d", n.Len())
+	}
+	var keys []string
+	n.Walk(func(n *AnyNode) bool {
+		keys = append(keys, string(n.Key()))
+		return true
+	})
+	if want := []string{"foo", "foobar"}; !equalStrings(keys, want) {
+		t.Errorf("expected Walk to visit %q, got %q", want, keys)
+	}
+
+	m := n.Merge((*AnyNode)(nil).PutString("foobar", nil))
+	if v, ok := m.LookupString("foobar"); v != nil || !ok {
+		t.Errorf(`expected merging nil to replace "foobar", got %v, %v`, v, ok)
+	}
+
+	m = n.DeleteString("foo")
+	if _, ok := m.LookupString("foo"); ok || m.Len() != 1 {
+		t.Errorf(`expected Delete to remove "foo": %#v`, m)
+	}
+
+	var buf bytes.Buffer
+	z := (*Node[int])(nil).PutString("zero", 0)
+	if _, err := z.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := ReadNode[int](&buf); err != nil || !Equal(r, z) || r.Len() != 1 {
+		t.Errorf("expected a zero value to survive encoding, got %#v, %v", r, err)
+	}
+}
+
+type cachedItem struct {
+	id    int
+	cache []byte
+}
+
+func (a cachedItem) Equal(other interface{}) bool {
+	b, ok := other.(cachedItem)
+	return ok && a.id == b.id
+}
+
+func TestEqualer(t *testing.T) {
+	n := new(Node[cachedItem]).PutString("foo", cachedItem{id: 1})
+
+	if m := n.PutString("foo", cachedItem{id: 1, cache: foo}); m != n {
+		t.Errorf("expected Equal to treat the values as equal")
+	}
+	if m := n.PutString("foo", cachedItem{id: 2}); m == n {
+		t.Errorf("expected Equal to tell the values apart")
+	}
+	if !Equal(n, new(Node[cachedItem]).PutString("foo", cachedItem{id: 1, cache: foo})) {
+		t.Errorf("expected Equal to use the values' Equal method")
+	}
+
+	tx := n.Txn()
+	tx.SetComparator(func(a, b cachedItem) bool { return false })
+	tx.PutString("foo", cachedItem{id: 1})
+	if m := tx.Commit(); m == n {
+		t.Errorf("expected the comparator to take precedence over Equal")
+	}
 }
 
-func assertExactNode(t *testing.T, expected, actual *Node) {
+func assertExactNode(t *testing.T, expected, actual *AnyNode) {
 	if actual != expected {
 		_, file, line, _ := runtime.Caller(1)
 		t.Errorf("expected %q (%p), got %q (%p) at %s:%d", expected.key, expected, actual.key, actual, file, line)
 	}
 }
 
-func node(key string, val interface{}, es edges) *Node {
-	return &Node{
-		key:   Key(key),
-		value: val,
-		edges: es,
+func node(key string, val interface{}, es anyEdges) *AnyNode {
+	n := &AnyNode{
+		key:     Key(key),
+		value:   val,
+		present: val != nil,
+		edges:   es,
 	}
+	n.resize()
+	return n
 }
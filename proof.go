@@ -0,0 +1,319 @@
+package trie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidProof is returned when a proof does not show what it is checked
+// for against a root digest.
+var ErrInvalidProof = errors.New("trie: invalid proof")
+
+// Proof shows that a key maps to a value, or that it is missing, in the trie
+// with a given digest. It holds the nodes on the path Get takes to the key,
+// each with the digests of its other edges, so the root digest can be
+// recomputed from the proof alone.
+//
+// A proof encodes as:
+//
+//	"trip"          magic
+//	byte            version
+//	uvarint         number of steps, from the root down
+//
+// and then for each step:
+//
+//	uvarint         length of the key suffix, then the suffix
+//	byte            1 if the node holds a value, 0 otherwise
+//	uvarint         length of the encoded value plus one, or zero if there
+//	                is none or it is left out, then the value
+//	uvarint         number of edges
+//	uvarint         index of the edge leading to the next step plus one, or
+//	                zero for the last step
+//	[n]byte         first byte of each edge's key suffix, in order
+//	[n][32]byte     digest of each edge, except the one leading to the next
+//	                step
+//
+// The value of the node holding a proven key is left out, as it is supplied
+// by the verifier.
+type Proof struct {
+	steps []proofStep
+}
+
+type proofStep struct {
+	suffix []byte
+	value  []byte // nil if the node has no value, or it is left out
+	has    bool
+	path   int // index of the edge leading to the next step, or -1
+	labels []byte
+	sums   []Digest // the digest at path is left zero
+}
+
+const (
+	proofMagic   = "trip"
+	proofVersion = 1
+)
+
+// Prove returns a proof that k maps to its value in n, or that it is missing,
+// encoding values with DefaultCodec. Check it with VerifyProof or
+// VerifyAbsence.
+func (n *Node[V]) Prove(k []byte) (*Proof, error) {
+	return (*Hasher[V])(nil).Prove(n, k)
+}
+
+// Prove is like Node.Prove, but encodes values with the hasher's codec.
+func (h *Hasher[V]) Prove(n *Node[V], k []byte) (*Proof, error) {
+	s := newSummer(h)
+	p := new(Proof)
+	for depth := 0; ; {
+		if n = n.canonical(); n == nil {
+			return p, nil
+		}
+		st := proofStep{suffix: n.key[depth:], has: n.present, path: -1}
+
+		// Find the edge to follow before hashing the others.
+		var next *Node[V]
+		if end := len(n.key); len(k) > end && bytes.HasPrefix(k, n.key) {
+			if i, nd := n.edges.get(k[end], end); nd != nil {
+				st.path, next = i, nd
+			}
+		}
+
+		st.labels = make([]byte, len(n.edges))
+		st.sums = make([]Digest, len(n.edges))
+		for i, nd := range n.edges {
+			st.labels[i] = nd.key[len(n.key)]
+			if i == st.path {
+				continue
+			}
+			var err error
+			if st.sums[i], err = s.node(nd, len(n.key)); err != nil {
+				return nil, err
+			}
+		}
+
+		if n.present && (next != nil || !bytes.Equal(n.key, k)) {
+			val, err := s.value(n)
+			if err != nil {
+				return nil, err
+			}
+			st.value = bytes.Clone(val)
+			if st.value == nil {
+				st.value = []byte{}
+			}
+		}
+
+		p.steps = append(p.steps, st)
+		if next == nil {
+			return p, nil
+		}
+		n, depth = next, len(n.key)
+	}
+}
+
+// VerifyProof checks that p shows k mapping to v in the trie with the digest
+// root, encoding v with DefaultCodec. It returns ErrInvalidProof if it does
+// not.
+func VerifyProof[V any](root Digest, k []byte, v V, p *Proof) error {
+	return (*Hasher[V])(nil).VerifyProof(root, k, v, p)
+}
+
+// VerifyProof is like the function of the same name, but encodes v with the
+// hasher's codec.
+func (h *Hasher[V]) VerifyProof(root Digest, k []byte, v V, p *Proof) error {
+	val, err := newSummer(h).codec.AppendValue(nil, v)
+	if err != nil {
+		return err
+	}
+	return p.verify(root, k, val, true)
+}
+
+// VerifyAbsence checks that p shows k missing from the trie with the digest
+// root. It returns ErrInvalidProof if it does not.
+func VerifyAbsence(root Digest, k []byte, p *Proof) error {
+	return p.verify(root, k, nil, false)
+}
+
+func (p *Proof) verify(root Digest, k, val []byte, present bool) error {
+	if p == nil || len(p.steps) == 0 {
+		if root == (Digest{}) && !present {
+			return nil
+		}
+		return ErrInvalidProof
+	}
+
+	// Check that the steps follow k, and how the last one ends the search.
+	depth := 0
+	for i, st := range p.steps {
+		end := depth + len(st.suffix)
+		matched := len(k) >= end && bytes.Equal(st.suffix, k[depth:end])
+		if i < len(p.steps)-1 {
+			if !matched || len(k) == end || st.path < 0 || st.path >= len(st.labels) || st.labels[st.path] != k[end] {
+				return ErrInvalidProof
+			}
+			depth = end
+			continue
+		}
+		if st.path >= 0 {
+			return ErrInvalidProof
+		}
+		// The search for k ends here only if k diverges from the node, stops
+		// at it, or has no edge to follow. An edge for the next byte of k means
+		// the proof was cut short.
+		if matched && len(k) > end && bytes.IndexByte(st.labels, k[end]) >= 0 {
+			return ErrInvalidProof
+		}
+		if found := matched && len(k) == end && st.has; found != present {
+			return ErrInvalidProof
+		}
+	}
+
+	// Hash the steps from the bottom up.
+	var sum Digest
+	var buf []byte
+	for i := len(p.steps) - 1; i >= 0; i-- {
+		st := p.steps[i]
+		// Only the value of the proven key is left out.
+		value := st.value
+		if i == len(p.steps)-1 && present {
+			if value != nil {
+				return ErrInvalidProof
+			}
+			value = val
+		} else if st.has && value == nil {
+			return ErrInvalidProof
+		}
+		sums := st.sums
+		if st.path >= 0 {
+			sums = append([]Digest(nil), st.sums...)
+			sums[st.path] = sum
+		}
+		buf = appendNodeHash(buf[:0], st.suffix, value, st.has, st.labels, sums)
+		sum = sha256.Sum256(buf)
+	}
+	if sum != root {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// MarshalBinary encodes p in the format described on Proof.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	b := append([]byte(proofMagic), proofVersion)
+	b = binary.AppendUvarint(b, uint64(len(p.steps)))
+	for _, st := range p.steps {
+		b = binary.AppendUvarint(b, uint64(len(st.suffix)))
+		b = append(b, st.suffix...)
+		if st.has {
+			b = append(b, flagValue)
+		} else {
+			b = append(b, 0)
+		}
+		if st.value != nil {
+			b = binary.AppendUvarint(b, uint64(len(st.value))+1)
+			b = append(b, st.value...)
+		} else {
+			b = append(b, 0)
+		}
+		b = binary.AppendUvarint(b, uint64(len(st.labels)))
+		b = binary.AppendUvarint(b, uint64(st.path+1))
+		b = append(b, st.labels...)
+		for i := range st.sums {
+			if i != st.path {
+				b = append(b, st.sums[i][:]...)
+			}
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary, returning
+// ErrInvalidFormat if it is malformed.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	r := proofReader{b: data}
+	if string(r.bytes(len(proofMagic))) != proofMagic || r.byte() != proofVersion {
+		return ErrInvalidFormat
+	}
+	count := r.uvarint()
+	if count > uint64(len(data)) {
+		return ErrInvalidFormat
+	}
+	steps := make([]proofStep, 0, count)
+	for ; count > 0 && !r.bad; count-- {
+		var st proofStep
+		st.suffix = bytes.Clone(r.bytes(int(r.uvarint())))
+		switch r.byte() {
+		case flagValue:
+			st.has = true
+		case 0:
+		default:
+			return ErrInvalidFormat
+		}
+		if n := r.uvarint(); n > 0 {
+			if !st.has {
+				return ErrInvalidFormat
+			}
+			st.value = bytes.Clone(r.bytes(int(n - 1)))
+			if st.value == nil {
+				st.value = []byte{}
+			}
+		}
+		n := r.uvarint()
+		if n > 256 {
+			return ErrInvalidFormat
+		}
+		path := r.uvarint()
+		if path > n {
+			return ErrInvalidFormat
+		}
+		st.path = int(path) - 1
+		st.labels = bytes.Clone(r.bytes(int(n)))
+		st.sums = make([]Digest, n)
+		for i := range st.sums {
+			if i != st.path {
+				copy(st.sums[i][:], r.bytes(len(Digest{})))
+			}
+		}
+		steps = append(steps, st)
+	}
+	if r.bad || len(r.b) > 0 {
+		return ErrInvalidFormat
+	}
+	p.steps = steps
+	return nil
+}
+
+// proofReader reads the parts of an encoded proof, remembering whether it ran
+// out of data.
+type proofReader struct {
+	b   []byte
+	bad bool
+}
+
+func (r *proofReader) bytes(n int) []byte {
+	if n < 0 || n > len(r.b) {
+		r.bad, r.b = true, nil
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *proofReader) byte() byte {
+	if b := r.bytes(1); len(b) == 1 {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *proofReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.bad, r.b = true, nil
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
@@ -0,0 +1,163 @@
+package trie
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProve(t *testing.T) {
+	// Leave out "" and "ab" so some searches end on valueless nodes.
+	var keys []string
+	for _, k := range iterKeys {
+		if k != "" && k != "ab" {
+			keys = append(keys, k)
+		}
+	}
+	n := buildIterTrie(keys)
+	root := mustHash(t, n)
+
+	for i, k := range keys {
+		p, err := n.Prove([]byte(k))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyProof(root, []byte(k), i+1, p); err != nil {
+			t.Errorf("%q: expected the proof to verify, got %v", k, err)
+		}
+		if err := VerifyProof(root, []byte(k), i+2, p); err != ErrInvalidProof {
+			t.Errorf("%q: expected a wrong value to fail, got %v", k, err)
+		}
+		if err := VerifyAbsence(root, []byte(k), p); err != ErrInvalidProof {
+			t.Errorf("%q: expected an inclusion proof not to show absence, got %v", k, err)
+		}
+	}
+
+	for _, k := range []string{"", "0", "aa", "ab", "abb", "abz", "az", "bb", "fo", "foo b", "fooz", "z"} {
+		p, err := n.Prove([]byte(k))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyAbsence(root, []byte(k), p); err != nil {
+			t.Errorf("%q: expected the proof to show absence, got %v", k, err)
+		}
+		if err := VerifyProof(root, []byte(k), 0, p); err != ErrInvalidProof {
+			t.Errorf("%q: expected an absence proof not to show a value, got %v", k, err)
+		}
+	}
+}
+
+func TestProveWrongRoot(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	p, err := n.Prove([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := mustHash(t, n.PutString("foo", 20))
+	if err := VerifyProof(other, []byte("abc"), 4, p); err != ErrInvalidProof {
+		t.Errorf("expected a proof against another root to fail, got %v", err)
+	}
+	if err := VerifyProof(mustHash(t, n), []byte("abd"), 4, p); err != ErrInvalidProof {
+		t.Errorf("expected a proof for another key to fail, got %v", err)
+	}
+}
+
+func TestProveEmpty(t *testing.T) {
+	var n *Node[int]
+	p, err := n.Prove(foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAbsence(Digest{}, foo, p); err != nil {
+		t.Errorf("expected any key to be missing from an empty trie, got %v", err)
+	}
+	if err := VerifyProof(Digest{}, foo, 0, p); err != ErrInvalidProof {
+		t.Errorf("expected no key to be found in an empty trie, got %v", err)
+	}
+}
+
+func TestProofEncoding(t *testing.T) {
+	n := buildIterTrie(iterKeys).PutString("empty", 0)
+	root := mustHash(t, n)
+
+	for _, k := range []string{"foo bar", "fooz"} {
+		p, err := n.Prove([]byte(k))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var q Proof
+		if err := q.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := q.MarshalBinary(); string(again) != string(data) {
+			t.Errorf("%q: expected the encoding to be stable", k)
+		}
+		verify := func(q *Proof) error {
+			if _, ok := n.LookupString(k); ok {
+				return VerifyProof(root, []byte(k), 11, q)
+			}
+			return VerifyAbsence(root, []byte(k), q)
+		}
+		if err := verify(&q); err != nil {
+			t.Errorf("%q: expected the decoded proof to verify, got %v", k, err)
+		}
+
+		if err := q.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%q: expected a truncated proof to be rejected, got %v", k, err)
+		}
+		// Flipping any byte must be caught, either here or when verifying.
+		for i := range data {
+			data[i] ^= 1
+			if q.UnmarshalBinary(data) == nil && verify(&q) == nil {
+				t.Errorf("%q: expected flipping byte %d to be caught", k, i)
+			}
+			data[i] ^= 1
+		}
+	}
+}
+
+func TestHasherProve(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	h := NewHasher[int](decimalCodec{})
+	root, err := h.Sum(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := h.Prove(n, []byte("ba"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.VerifyProof(root, []byte("ba"), 7, p); err != nil {
+		t.Errorf("expected the proof to verify with the same codec, got %v", err)
+	}
+	if err := VerifyProof(root, []byte("ba"), 7, p); err != ErrInvalidProof {
+		t.Errorf("expected the proof not to verify with another codec, got %v", err)
+	}
+}
+
+func TestProveLongerKey(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	root := mustHash(t, n)
+
+	// "ab" has edges for "abc" and "abd", so its proof must not stand in for
+	// keys below it.
+	p, err := n.Prove([]byte("ab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"abc", "abcz", "abd", "abz"} {
+		if err := VerifyProof(root, []byte(k), 3, p); err != ErrInvalidProof {
+			t.Errorf("%q: expected the proof of \"ab\" not to show a value, got %v", k, err)
+		}
+	}
+	for _, k := range []string{"abc", "abcz", "abd"} {
+		if err := VerifyAbsence(root, []byte(k), p); err != ErrInvalidProof {
+			t.Errorf("%q: expected the proof of \"ab\" not to show absence, got %v", k, err)
+		}
+	}
+}
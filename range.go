@@ -0,0 +1,111 @@
+package trie
+
+import "bytes"
+
+// Bound is one end of a key range. A nil *Bound leaves that end of the range
+// open.
+type Bound struct {
+	Key       []byte
+	Exclusive bool
+}
+
+// Inclusive returns a bound that includes k.
+func Inclusive(k []byte) *Bound {
+	return &Bound{Key: k}
+}
+
+// Exclusive returns a bound that excludes k.
+func Exclusive(k []byte) *Bound {
+	return &Bound{Key: k, Exclusive: true}
+}
+
+// Range calls fn for every value with lo <= key < hi, in key order, until fn
+// returns false. A nil lo or hi leaves that end of the range open.
+func (n *Node[V]) Range(lo, hi []byte, fn func(*Node[V]) bool) {
+	var l, h *Bound
+	if lo != nil {
+		l = Inclusive(lo)
+	}
+	if hi != nil {
+		h = Exclusive(hi)
+	}
+	n.RangeBounds(l, h, fn)
+}
+
+// RangeString is like Range, but takes string keys. An empty hi leaves the
+// upper end of the range open.
+func (n *Node[V]) RangeString(lo, hi string, fn func(*Node[V]) bool) {
+	var h []byte
+	if hi != "" {
+		h = []byte(hi)
+	}
+	n.Range([]byte(lo), h, fn)
+}
+
+// RangeBounds calls fn for every value between lo and hi, in key order, until
+// fn returns false. Only the paths leading into the range are visited.
+func (n *Node[V]) RangeBounds(lo, hi *Bound, fn func(*Node[V]) bool) {
+	it := n.Iterator()
+	for ok := it.seekBound(lo); ok && hi.above(it.Key()); ok = it.Next() {
+		if !fn(it.node()) {
+			return
+		}
+	}
+}
+
+// RangeBoundsReverse is like RangeBounds, but visits the values in reverse
+// key order.
+func (n *Node[V]) RangeBoundsReverse(lo, hi *Bound, fn func(*Node[V]) bool) {
+	it := n.Iterator()
+	for ok := it.seekBoundReverse(hi); ok && lo.below(it.Key()); ok = it.Prev() {
+		if !fn(it.node()) {
+			return
+		}
+	}
+}
+
+// seekBound moves to the first key within the lower bound b.
+func (it *Iterator[V]) seekBound(b *Bound) bool {
+	if b == nil {
+		return it.First()
+	}
+	if !it.SeekGE(b.Key) {
+		return false
+	}
+	if b.Exclusive && bytes.Equal(it.Key(), b.Key) {
+		return it.Next()
+	}
+	return true
+}
+
+// seekBoundReverse moves to the last key within the upper bound b.
+func (it *Iterator[V]) seekBoundReverse(b *Bound) bool {
+	if b == nil {
+		return it.Last()
+	}
+	if !it.SeekLE(b.Key) {
+		return false
+	}
+	if b.Exclusive && bytes.Equal(it.Key(), b.Key) {
+		return it.Prev()
+	}
+	return true
+}
+
+// below reports whether k is within b as a lower bound.
+func (b *Bound) below(k []byte) bool {
+	if b == nil {
+		return true
+	}
+	c := bytes.Compare(k, b.Key)
+	return c > 0 || c == 0 && !b.Exclusive
+}
+
+// above reports whether k is within b as an upper bound.
+func (b *Bound) above(k []byte) bool {
+	if b == nil {
+		return true
+	}
+	c := bytes.Compare(k, b.Key)
+	return c < 0 || c == 0 && !b.Exclusive
+}
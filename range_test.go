@@ -0,0 +1,72 @@
+package trie
+
+import "testing"
+
+func TestRange(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	collect := func(lo, hi *Bound) []string {
+		var got []string
+		n.RangeBounds(lo, hi, func(nd *Node[int]) bool {
+			got = append(got, string(nd.Key()))
+			return true
+		})
+		return got
+	}
+
+	table := []struct {
+		Lo, Hi *Bound
+		Keys   []string
+	}{
+		{Inclusive([]byte("ab")), Exclusive([]byte("b")), []string{"ab", "abc", "abd"}},
+		{Exclusive([]byte("ab")), Inclusive([]byte("b")), []string{"abc", "abd", "b"}},
+		{Inclusive([]byte("abz")), Inclusive([]byte("bab")), []string{"b", "ba", "bab"}},
+		{Inclusive([]byte("foo ")), nil, []string{"foo bar", "foo baz", "food"}},
+		{nil, Exclusive([]byte("a")), []string{""}},
+		{Exclusive([]byte("food")), nil, nil},
+		{Inclusive([]byte("c")), Exclusive([]byte("c")), nil},
+		{nil, nil, iterKeys},
+	}
+	for _, x := range table {
+		if got := collect(x.Lo, x.Hi); !equalStrings(got, x.Keys) {
+			t.Errorf("range %v to %v: expected %q, got %q", x.Lo, x.Hi, x.Keys, got)
+		}
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var got []string
+	n.RangeString("b", "", func(nd *Node[int]) bool {
+		got = append(got, string(nd.Key()))
+		return len(got) < 3
+	})
+	if want := []string{"b", "ba", "bab"}; !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRangeBoundsReverse(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	table := []struct {
+		Lo, Hi *Bound
+		Keys   []string
+	}{
+		{Inclusive([]byte("ab")), Exclusive([]byte("b")), []string{"abd", "abc", "ab"}},
+		{Exclusive([]byte("ab")), Inclusive([]byte("b")), []string{"b", "abd", "abc"}},
+		{nil, Exclusive([]byte("ab")), []string{"a", ""}},
+		{Inclusive([]byte("foo")), nil, []string{"food", "foo baz", "foo bar", "foo"}},
+	}
+	for _, x := range table {
+		var got []string
+		n.RangeBoundsReverse(x.Lo, x.Hi, func(nd *Node[int]) bool {
+			got = append(got, string(nd.Key()))
+			return true
+		})
+		if !equalStrings(got, x.Keys) {
+			t.Errorf("range %v to %v: expected %q, got %q", x.Lo, x.Hi, x.Keys, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build go1.23
+
+package trie
+
+import "iter"
+
+// All returns a sequence of every key and value, in key order.
+func (n *Node[V]) All() iter.Seq2[[]byte, V] {
+	return func(yield func([]byte, V) bool) {
+		it := n.Iterator()
+		for ok := it.First(); ok; ok = it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns a sequence of every key and value, in reverse key order.
+func (n *Node[V]) Backward() iter.Seq2[[]byte, V] {
+	return func(yield func([]byte, V) bool) {
+		it := n.Iterator()
+		for ok := it.Last(); ok; ok = it.Prev() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// PrefixAll returns a sequence of every key starting with p and its value, in
+// key order.
+func (n *Node[V]) PrefixAll(p []byte) iter.Seq2[[]byte, V] {
+	return n.Prefix(p).All()
+}
+
+// RangeAll returns a sequence of every key and value with lo <= key < hi, in
+// key order. A nil lo or hi leaves that end of the range open.
+func (n *Node[V]) RangeAll(lo, hi []byte) iter.Seq2[[]byte, V] {
+	return func(yield func([]byte, V) bool) {
+		n.Range(lo, hi, func(nd *Node[V]) bool {
+			return yield(nd.key, nd.value)
+		})
+	}
+}
+
+// PrefixBackward is like PrefixAll, but in reverse key order.
+func (n *Node[V]) PrefixBackward(p []byte) iter.Seq2[[]byte, V] {
+	return n.Prefix(p).Backward()
+}
+
+// RangeBackward is like RangeAll, but in reverse key order.
+func (n *Node[V]) RangeBackward(lo, hi []byte) iter.Seq2[[]byte, V] {
+	var l, h *Bound
+	if lo != nil {
+		l = Inclusive(lo)
+	}
+	if hi != nil {
+		h = Exclusive(hi)
+	}
+	return func(yield func([]byte, V) bool) {
+		n.RangeBoundsReverse(l, h, func(nd *Node[V]) bool {
+			return yield(nd.key, nd.value)
+		})
+	}
+}
+
+// Keys returns a sequence of every key, in order.
+func (n *Node[V]) Keys() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for k := range n.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a sequence of every value, in key order.
+func (n *Node[V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range n.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+//go:build go1.23
+
+package trie
+
+import "testing"
+
+func TestSeqAll(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var keys []string
+	for k, v := range n.All() {
+		if v != n.Get(k) {
+			t.Errorf("%q: expected %d, got %d", k, n.Get(k), v)
+		}
+		keys = append(keys, string(k))
+	}
+	if !equalStrings(keys, iterKeys) {
+		t.Errorf("expected %q, got %q", iterKeys, keys)
+	}
+
+	keys = keys[:0]
+	for k := range n.Backward() {
+		keys = append(keys, string(k))
+		if len(keys) == 3 {
+			break
+		}
+	}
+	if want := []string{"food", "foo baz", "foo bar"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+}
+
+func TestSeqPrefixAndRange(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var keys []string
+	for k := range n.PrefixAll([]byte("ab")) {
+		keys = append(keys, string(k))
+	}
+	if want := []string{"ab", "abc", "abd"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+
+	var sum int
+	for v := range n.Values() {
+		sum += v
+	}
+	if want := len(iterKeys) * (len(iterKeys) + 1) / 2; sum != want {
+		t.Errorf("expected values to sum to %d, got %d", want, sum)
+	}
+
+	keys = keys[:0]
+	for k := range n.RangeAll([]byte("b"), []byte("c")) {
+		keys = append(keys, string(k))
+	}
+	if want := []string{"b", "ba", "bab"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+
+	keys = keys[:0]
+	for k := range n.Keys() {
+		keys = append(keys, string(k))
+	}
+	if !equalStrings(keys, iterKeys) {
+		t.Errorf("expected %q, got %q", iterKeys, keys)
+	}
+}
+
+func TestSeqBackward(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var keys []string
+	for k := range n.PrefixBackward([]byte("foo")) {
+		keys = append(keys, string(k))
+	}
+	if want := []string{"food", "foo baz", "foo bar", "foo"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+
+	keys = keys[:0]
+	for k := range n.RangeBackward([]byte("a"), []byte("abd")) {
+		keys = append(keys, string(k))
+	}
+	if want := []string{"abc", "ab", "a"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+}
@@ -0,0 +1,163 @@
+package trie
+
+// Intersect returns a trie holding the keys found in both a and b. The value
+// of each is picked by fn, as in MergeFunc; a nil fn takes the one from b.
+// Subtrees that come through unchanged are shared with a or b.
+func Intersect[V any](a, b *Node[V], fn Resolver[V]) *Node[V] {
+	s := &setOp[V]{both: true, fn: fn}
+	return s.nodes(0, a, b)
+}
+
+// Subtract returns a trie holding the keys of a that are not in b. Keys found
+// in both are passed to fn, if not nil, and kept if it returns true. Subtrees
+// that come through unchanged are shared with a.
+func Subtract[V any](a, b *Node[V], fn Resolver[V]) *Node[V] {
+	s := &setOp[V]{onlyA: true, fn: fn}
+	return s.nodes(0, a, b)
+}
+
+// SymmetricDiff returns a trie holding the keys found in exactly one of a and
+// b. Keys found in both are passed to fn, if not nil, and kept if it returns
+// true. Subtrees that come through unchanged are shared with a or b.
+func SymmetricDiff[V any](a, b *Node[V], fn Resolver[V]) *Node[V] {
+	s := &setOp[V]{onlyA: true, onlyB: true, fn: fn}
+	return s.nodes(0, a, b)
+}
+
+// setOp walks two tries in lockstep, like differ, building the result of a
+// set operation bottom up.
+type setOp[V any] struct {
+	onlyA bool // keep keys found only in a
+	onlyB bool // keep keys found only in b
+	both  bool // keep keys found in both, when fn is nil
+	fn    Resolver[V]
+}
+
+func (s *setOp[V]) nodes(depth int, a, b *Node[V]) *Node[V] {
+	switch {
+	case a == nil:
+		return s.keep(b, s.onlyB)
+	case b == nil:
+		return s.keep(a, s.onlyA)
+	case a == b && s.fn == nil:
+		return s.keep(a, s.both)
+	}
+
+	n, short := a.key.commonBytesLen(b.key, depth)
+	switch {
+	case !short && n == len(b.key): // same key
+		v, has, from := s.values(a, b)
+		return s.build(a.key, v, has, from, s.edges(n, a.edges, b.edges), a, b)
+	case !short: // a is a prefix of b
+		v, has, from := s.values(a, nil)
+		return s.build(a.key, v, has, from, s.edges(n, a.edges, edges[V]{b}), a, nil)
+	case n == len(b.key): // b is a prefix of a
+		v, has, from := s.values(nil, b)
+		return s.build(b.key, v, has, from, s.edges(n, edges[V]{a}, b.edges), nil, b)
+	}
+
+	// The keys part ways, so each side only holds keys the other lacks.
+	x, y := s.keep(a, s.onlyA), s.keep(b, s.onlyB)
+	switch {
+	case x == nil:
+		return y
+	case y == nil:
+		return x
+	case x.key[n] > y.key[n]:
+		x, y = y, x
+	}
+	var zero V
+	return (*Txn[V])(nil).newNode(a.key[:n], zero, false, edges[V]{x, y})
+}
+
+// edges combines two sets of edges by walking them in lockstep.
+func (s *setOp[V]) edges(depth int, a, b edges[V]) edges[V] {
+	var es edges[V]
+	add := func(n *Node[V]) {
+		if n != nil {
+			es = append(es, n)
+		}
+	}
+	for len(a) > 0 && len(b) > 0 {
+		switch x, y := a[0].key[depth], b[0].key[depth]; {
+		case x < y:
+			add(s.keep(a[0], s.onlyA))
+			a = a[1:]
+		case x > y:
+			add(s.keep(b[0], s.onlyB))
+			b = b[1:]
+		default:
+			add(s.nodes(depth+1, a[0], b[0]))
+			a, b = a[1:], b[1:]
+		}
+	}
+	for _, n := range a {
+		add(s.keep(n, s.onlyA))
+	}
+	for _, n := range b {
+		add(s.keep(n, s.onlyB))
+	}
+	return es
+}
+
+// values picks the value for a node present on one or both sides, if it is
+// kept, along with the node it was taken from. The node is nil if the value
+// is new, or if there is none.
+func (s *setOp[V]) values(a, b *Node[V]) (v V, has bool, from *Node[V]) {
+	hasA := a != nil && a.hasValue()
+	hasB := b != nil && b.hasValue()
+	switch {
+	case hasA && hasB && s.fn != nil:
+		v, has = s.fn(a.key, a.value, b.value)
+	case hasA && hasB && s.both:
+		v, has, from = b.value, true, b
+	case hasA && !hasB && s.onlyA:
+		v, has, from = a.value, true, a
+	case hasB && !hasA && s.onlyB:
+		v, has, from = b.value, true, b
+	}
+	return
+}
+
+// build returns a node for key with edges es, holding v if has is true. It
+// reuses a or b if it already is that node, and returns nil if the node would
+// be empty.
+func (s *setOp[V]) build(key Key, v V, has bool, from *Node[V], es edges[V], a, b *Node[V]) *Node[V] {
+	if !has {
+		switch len(es) {
+		case 0:
+			return nil
+		case 1:
+			return es[0]
+		}
+	}
+	for _, n := range [...]*Node[V]{a, b} {
+		if n == nil || !sameEdges(n.edges, es) {
+			continue
+		}
+		if from == n || (!has && !n.hasValue()) {
+			return n
+		}
+	}
+	return (*Txn[V])(nil).newNode(key, v, has, es)
+}
+
+func (s *setOp[V]) keep(n *Node[V], ok bool) *Node[V] {
+	if ok {
+		return n
+	}
+	return nil
+}
+
+// sameEdges reports whether a and b hold the very same nodes.
+func sameEdges[V any](a, b edges[V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
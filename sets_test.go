@@ -0,0 +1,134 @@
+package trie
+
+import (
+	"sort"
+	"testing"
+)
+
+var (
+	setKeysA = []string{"", "a", "ab", "abc", "b", "bab", "foo", "foo bar", "food"}
+	setKeysB = []string{"a", "abc", "abd", "ba", "bab", "c", "foo baz", "food"}
+)
+
+// buildSetTrie is like buildIterTrie, but gives every key a value derived
+// from the key itself, so the same key holds the same value in both tries.
+func buildSetTrie(keys []string, scale int) *Node[int] {
+	tx := new(Txn[int])
+	for _, k := range keys {
+		tx.PutString(k, scale*(len(k)+1))
+	}
+	return tx.Commit()
+}
+
+func setKeys(n *Node[int]) []string {
+	var keys []string
+	n.Walk(func(n *Node[int]) bool {
+		keys = append(keys, string(n.Key()))
+		return true
+	})
+	return keys
+}
+
+func TestSetOps(t *testing.T) {
+	a := buildSetTrie(setKeysA, 1)
+	b := buildSetTrie(setKeysB, 10)
+
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, k := range setKeysA {
+		inA[k] = true
+	}
+	for _, k := range setKeysB {
+		inB[k] = true
+	}
+	filter := func(keep func(k string) bool) []string {
+		var keys []string
+		for k := range inA {
+			if keep(k) {
+				keys = append(keys, k)
+			}
+		}
+		for k := range inB {
+			if !inA[k] && keep(k) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	tests := []struct {
+		name string
+		got  *Node[int]
+		want []string
+	}{
+		{"Intersect", Intersect(a, b, nil), filter(func(k string) bool { return inA[k] && inB[k] })},
+		{"Subtract", Subtract(a, b, nil), filter(func(k string) bool { return inA[k] && !inB[k] })},
+		{"SymmetricDiff", SymmetricDiff(a, b, nil), filter(func(k string) bool { return inA[k] != inB[k] })},
+	}
+	for _, tt := range tests {
+		if got := setKeys(tt.got); !equalStrings(got, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+		checkSizes(t, tt.got)
+	}
+
+	if v := Intersect(a, b, nil).GetString("food"); v != 50 {
+		t.Errorf(`expected Intersect to take "food" from b, got %d`, v)
+	}
+}
+
+func TestSetOpsCombine(t *testing.T) {
+	a := buildSetTrie(setKeysA, 1)
+	b := buildSetTrie(setKeysB, 10)
+	sub := func(k []byte, x, y int) (int, bool) {
+		return y - x, string(k) != "a"
+	}
+
+	n := Intersect(a, b, sub)
+	if got, want := setKeys(n), []string{"abc", "bab", "food"}; !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if v := n.GetString("abc"); v != 36 {
+		t.Errorf(`expected "abc" to be 36, got %d`, v)
+	}
+
+	n = Subtract(a, b, sub)
+	if got, want := setKeys(n), []string{"", "ab", "abc", "b", "bab", "foo", "foo bar", "food"}; !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	checkSizes(t, n)
+}
+
+func TestSetOpsSharing(t *testing.T) {
+	a := buildSetTrie(setKeysA, 1)
+	b := a.DeleteString("abc").PutString("c", 1)
+
+	if n := Intersect(a, a, nil); n != a {
+		t.Errorf("expected intersecting a trie with itself to return it")
+	}
+	if n := Subtract(a, a, nil); n != nil {
+		t.Errorf("expected subtracting a trie from itself to be empty, got %q", setKeys(n))
+	}
+
+	n := Intersect(a, b, nil)
+	if n.PrefixString("foo") != a.PrefixString("foo") {
+		t.Errorf("expected an untouched subtree to be shared")
+	}
+	if got, want := setKeys(SymmetricDiff(a, b, nil)), []string{"abc", "c"}; !equalStrings(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSetOpsNil(t *testing.T) {
+	a := buildSetTrie(setKeysA, 1)
+
+	if Intersect(a, nil, nil) != nil || Intersect(nil, a, nil) != nil {
+		t.Errorf("expected intersecting with nil to be empty")
+	}
+	if Subtract(a, nil, nil) != a || Subtract(nil, a, nil) != nil {
+		t.Errorf("expected subtracting nil to return a, and subtracting from nil to be empty")
+	}
+	if SymmetricDiff(a, nil, nil) != a || SymmetricDiff(nil, a, nil) != a {
+		t.Errorf("expected the symmetric difference with nil to return a")
+	}
+}
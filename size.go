@@ -0,0 +1,66 @@
+package trie
+
+// Len returns the number of values in the trie.
+func (n *Node[V]) Len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// Rank returns the number of keys less than k.
+func (n *Node[V]) Rank(k []byte) (r int) {
+	for depth := 0; n != nil; {
+		d, short := n.key.commonBytesLen(k, depth)
+		switch {
+		case d == len(k): // everything under n is >= k
+			return r
+		case short:
+			if n.key[d] < k[d] {
+				r += n.size
+			}
+			return r
+		}
+		if n.hasValue() {
+			r++
+		}
+		depth = d
+
+		i, child := n.edges.get(k[depth], depth)
+		for _, e := range n.edges[:i] {
+			r += e.size
+		}
+		n = child
+	}
+	return r
+}
+
+// RankString is like Rank, but takes a string key.
+func (n *Node[V]) RankString(k string) int {
+	return n.Rank([]byte(k))
+}
+
+// Select returns the key and value at index i in key order, counting from
+// zero. It returns false if i is out of range.
+func (n *Node[V]) Select(i int) (key []byte, value V, ok bool) {
+	if i < 0 || i >= n.Len() {
+		return
+	}
+top:
+	for {
+		if n.hasValue() {
+			if i == 0 {
+				return n.key, n.value, true
+			}
+			i--
+		}
+		for _, e := range n.edges {
+			if i < e.size {
+				n = e
+				continue top
+			}
+			i -= e.size
+		}
+		panic("Select: subtree sizes are inconsistent")
+	}
+}
@@ -0,0 +1,86 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestLenRankSelect(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	if l := n.Len(); l != len(iterKeys) {
+		t.Errorf("expected Len to be %d, got %d", len(iterKeys), l)
+	}
+	for i, k := range iterKeys {
+		if r := n.RankString(k); r != i {
+			t.Errorf("expected Rank(%q) to be %d, got %d", k, i, r)
+		}
+		key, v, ok := n.Select(i)
+		if !ok || string(key) != k || v != i+1 {
+			t.Errorf("expected Select(%d) to be %q, got %q", i, k, key)
+		}
+	}
+	for _, k := range []string{"0", "aa", "abz", "bb", "foo b", "fooz", "zz"} {
+		if r, want := n.RankString(k), sort.SearchStrings(iterKeys, k); r != want {
+			t.Errorf("expected Rank(%q) to be %d, got %d", k, want, r)
+		}
+	}
+	if _, _, ok := n.Select(len(iterKeys)); ok {
+		t.Errorf("expected Select past the end to fail")
+	}
+	if _, _, ok := n.Select(-1); ok {
+		t.Errorf("expected Select(-1) to fail")
+	}
+}
+
+func TestLenMaintained(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	keys := make([]string, 200)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%x", rnd.Intn(1<<12))
+	}
+
+	tx := new(Txn[int])
+	other := new(Txn[int])
+	for i, k := range keys {
+		switch i % 4 {
+		case 0, 1:
+			tx.PutString(k, i+1)
+		case 2:
+			tx.DeleteString(keys[rnd.Intn(i)])
+		case 3:
+			other.PutString(k, i+1)
+		}
+	}
+	n := tx.Commit()
+	n = n.Merge(other.Commit())
+
+	var count int
+	n.Walk(func(*Node[int]) bool {
+		count++
+		return true
+	})
+	if l := n.Len(); l != count {
+		t.Errorf("expected Len to be %d, got %d", count, l)
+	}
+	checkSizes(t, n)
+}
+
+func checkSizes[V any](t *testing.T, n *Node[V]) int {
+	if n == nil {
+		return 0
+	}
+	size := 0
+	if n.hasValue() {
+		size = 1
+	}
+	for _, e := range n.edges {
+		size += checkSizes(t, e)
+	}
+	if n.size != size {
+		t.Errorf("expected %q to have size %d, got %d", n.key, size, n.size)
+	}
+	return size
+}
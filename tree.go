@@ -0,0 +1,61 @@
+package trie
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Tree holds the root of a trie shared between goroutines. Readers take
+// lock-free snapshots, while writers go through transactions whose results
+// are published atomically.
+type Tree[V any] struct {
+	root atomic.Pointer[Node[V]]
+	mu   sync.Mutex // serializes Update
+}
+
+// NewTree returns a Tree holding root.
+func NewTree[V any](root *Node[V]) *Tree[V] {
+	t := new(Tree[V])
+	t.root.Store(root)
+	return t
+}
+
+// Snapshot returns the current root. It never changes, whatever is written
+// to the Tree afterwards.
+func (t *Tree[V]) Snapshot() *Node[V] {
+	return t.root.Load()
+}
+
+// Update runs fn in a transaction on the current root and publishes the
+// result, unless fn returns an error, in which case the transaction is
+// aborted and the error returned. Calls to Update run one at a time. If an
+// UpdateCAS commits while fn runs, fn is run again on the new root.
+func (t *Tree[V]) Update(fn func(*Txn[V]) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.UpdateCAS(fn)
+}
+
+// UpdateCAS is like Update, but does not wait for other writers. It runs fn
+// on the current root and publishes the result only if the root is still the
+// same, running fn again on the new root otherwise. fn must be safe to run
+// more than once.
+func (t *Tree[V]) UpdateCAS(fn func(*Txn[V]) error) error {
+	for {
+		old := t.root.Load()
+		tx := old.Txn()
+		if err := fn(tx); err != nil {
+			tx.Abort()
+			return err
+		}
+		root := tx.commit()
+		if root == old {
+			return nil
+		}
+		if t.root.CompareAndSwap(old, root) {
+			notifyWatches(0, old, root)
+			return nil
+		}
+	}
+}
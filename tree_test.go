@@ -0,0 +1,77 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTreeUpdate(t *testing.T) {
+	tree := NewTree(buildIterTrie(iterKeys))
+	snap := tree.Snapshot()
+	_, ch := snap.GetWatchString("abc")
+
+	err := tree.Update(func(tx *Txn[int]) error {
+		tx.PutString("abc", 40)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := tree.Snapshot().GetString("abc"); v != 40 {
+		t.Errorf(`expected "abc" to be 40, got %d`, v)
+	}
+	if v := snap.GetString("abc"); v != 4 {
+		t.Errorf(`expected the old snapshot to keep "abc" at 4, got %d`, v)
+	}
+	if !isClosed(ch) {
+		t.Errorf("expected the watch to be closed by the update")
+	}
+
+	failed := errors.New("failed")
+	err = tree.Update(func(tx *Txn[int]) error {
+		tx.PutString("abc", 50)
+		return failed
+	})
+	if err != failed {
+		t.Errorf("expected the error from fn, got %v", err)
+	}
+	if v := tree.Snapshot().GetString("abc"); v != 40 {
+		t.Errorf(`expected the failed update to be discarded, got %d`, v)
+	}
+}
+
+func TestTreeConcurrent(t *testing.T) {
+	tree := NewTree[int](nil)
+
+	const writers, writes = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				update := tree.Update
+				if i%2 == 1 {
+					update = tree.UpdateCAS
+				}
+				update(func(tx *Txn[int]) error {
+					tx.PutString(fmt.Sprintf("%d/%d", w, i), i+1)
+					tx.PutString("count", tx.GetString("count")+1)
+					return nil
+				})
+				tree.Snapshot().Len()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	root := tree.Snapshot()
+	if n := root.GetString("count"); n != writers*writes {
+		t.Errorf("expected count to be %d, got %d", writers*writes, n)
+	}
+	if l := root.Len(); l != writers*writes+1 {
+		t.Errorf("expected %d values, got %d", writers*writes+1, l)
+	}
+}
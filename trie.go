@@ -5,7 +5,31 @@ import (
 	"reflect"
 )
 
-func Equal(a, b *Node) bool {
+// Comparator reports whether two values should be considered equal. It is
+// used to decide when a write can reuse an existing node.
+type Comparator[V any] func(a, b V) bool
+
+// Equaler is implemented by values that know how to compare themselves, such
+// as types holding caches that reflect.DeepEqual would look into. Without a
+// Comparator, values implementing it are compared by calling Equal.
+type Equaler interface {
+	Equal(other interface{}) bool
+}
+
+// AnyNode is the untyped trie, kept for code written against the original
+// interface{} API.
+type AnyNode = Node[interface{}]
+
+// AnyTxn is the transaction type for AnyNode.
+type AnyTxn = Txn[interface{}]
+
+func Equal[V any](a, b *Node[V]) bool {
+	return EqualFunc(a, b, nil)
+}
+
+// EqualFunc is like Equal, but compares values with eq. A nil eq falls back
+// to Equaler, then reflect.DeepEqual.
+func EqualFunc[V any](a, b *Node[V], eq Comparator[V]) bool {
 	if a == b {
 		return true
 	}
@@ -13,14 +37,18 @@ func Equal(a, b *Node) bool {
 		debugf("Equal: different key: %q != %q", a.key, b.key)
 		return false
 	}
-	if !reflect.DeepEqual(a.value, b.value) {
+	if a.present != b.present {
+		debugf("Equal: different presence for %q: %v != %v", a.key, a.present, b.present)
+		return false
+	}
+	if !eq.equal(a.value, b.value) {
 		debugf("Equal: different values for %q: %#v != %#v", a.key, a.value, b.value)
 		return false
 	}
-	return edgesEqual(a.edges, b.edges)
+	return edgesEqual(a.edges, b.edges, eq)
 }
 
-func edgesEqual(a, b edges) bool {
+func edgesEqual[V any](a, b edges[V], eq Comparator[V]) bool {
 	if len(a) != len(b) {
 		debugf("edgesEqual: different lengths; %d != %d", len(a), len(b))
 		return false
@@ -37,9 +65,22 @@ func edgesEqual(a, b edges) bool {
 		return false
 	}
 	for i, e := range a {
-		if !Equal(e, b[i]) {
+		if !EqualFunc(e, b[i], eq) {
 			return false
 		}
 	}
 	return true
 }
+
+// equal compares a and b with the comparator. If there is none, a is asked to
+// compare itself if it is an Equaler, and reflect.DeepEqual is the last
+// resort.
+func (eq Comparator[V]) equal(a, b V) bool {
+	if eq != nil {
+		return eq(a, b)
+	}
+	if x, ok := any(a).(Equaler); ok {
+		return x.Equal(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -34,7 +34,7 @@ func BenchmarkGetLongCompetitor(b *testing.B) {
 }
 
 func BenchmarkDelete(b *testing.B) {
-	x := &Node{key: fooBar}
+	x := &AnyNode{key: fooBar}
 	for i := 0; i < b.N; i++ {
 		x.Delete(fooBar)
 	}
@@ -71,7 +71,7 @@ func BenchmarkDenseCopy(b *testing.B) {
 }
 
 func BenchmarkPutNew(b *testing.B) {
-	tree := &Node{key: fooBar}
+	tree := &AnyNode{key: fooBar}
 	for i := 0; i < b.N; i++ {
 		tree.Put(fooBaz, 1)
 	}
@@ -79,7 +79,7 @@ func BenchmarkPutNew(b *testing.B) {
 
 func BenchmarkPutLong(b *testing.B) {
 	keys := randomKeys(1024)
-	tree := &Node{key: fooBar}
+	tree := &AnyNode{key: fooBar}
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -98,7 +98,7 @@ func BenchmarkPutLongCompetitor(b *testing.B) {
 }
 
 func BenchmarkPutExisting(b *testing.B) {
-	x := (&Node{key: fooBar, value: 1}).Put(fooBaz, 1)
+	x := node("foo bar", 1, nil).Put(fooBaz, 1)
 	for i := 0; i < b.N; i++ {
 		x.Put(fooBaz, 1)
 	}
@@ -161,8 +161,8 @@ func BenchmarkRandomMap(b *testing.B) {
 	}
 }
 
-func countTree(root *Node) (nodes, edges int) {
-	root.Walk(func(n *Node) bool {
+func countTree(root *AnyNode) (nodes, edges int) {
+	root.Walk(func(n *AnyNode) bool {
 		nodes += 1
 		edges += len(n.edges)
 		return true
@@ -178,8 +178,8 @@ func buildMap(keys [][]byte) map[string]int {
 	return m
 }
 
-func buildTree(keys [][]byte) *Node {
-	tx := new(Txn)
+func buildTree(keys [][]byte) *AnyNode {
+	tx := new(AnyTxn)
 	tx.Prealloc(len(keys) + 300)
 	// newTxn(len(keys) + 300)
 	for _, k := range keys {
@@ -1,35 +1,154 @@
 package trie
 
-type Txn struct {
-	root *Node
-	mut  map[*Node]bool
+import "errors"
+
+var (
+	// ErrTxnClosed is the value of the panic raised by using a Txn after
+	// Commit or Abort.
+	ErrTxnClosed = errors.New("trie: transaction already committed or aborted")
+
+	// ErrInvalidSavepoint is returned by RollbackTo for a savepoint that does
+	// not belong to the transaction, or that was undone by an earlier
+	// rollback.
+	ErrInvalidSavepoint = errors.New("trie: invalid savepoint")
+)
+
+// Txn batches changes to a trie. Nodes created by the transaction are changed
+// in place by later writes until Commit, so a batch costs less than the same
+// writes made through Node.Put.
+//
+// Once Commit or Abort has been called, every method that changes the
+// transaction panics with ErrTxnClosed.
+type Txn[V any] struct {
+	root    *Node[V]
+	base    *Node[V] // root as of the start of the transaction
+	mut     map[*Node[V]]bool
+	eq      Comparator[V]
+	changes []change[V] // nil unless tracking changes
+	resolve Resolver[V] // set during MergeFunc
+	dropped []Key       // keys the resolver deleted
+	saves   []int       // ids of the savepoints that can be rolled back to
+	nextID  int
+	closed  bool
 }
 
-func (t *Txn) Prealloc(n int) {
-	t.mut = make(map[*Node]bool, n)
+// Savepoint is the state of a transaction at a point it can be rolled back
+// to.
+type Savepoint[V any] struct {
+	txn     *Txn[V]
+	root    *Node[V]
+	changes int
+	id      int
 }
 
-func (t *Txn) Commit() *Node {
-	t.mut = nil
+func (t *Txn[V]) Prealloc(n int) {
+	t.checkOpen()
+	t.mut = make(map[*Node[V]]bool, n)
+}
+
+// SetComparator sets the function used to decide whether a Put changes an
+// existing value. Without one, values implementing Equaler compare
+// themselves, and anything else is compared with reflect.DeepEqual.
+func (t *Txn[V]) SetComparator(eq Comparator[V]) {
+	t.checkOpen()
+	t.eq = eq
+}
+
+// Txn starts a transaction on top of n.
+func (n *Node[V]) Txn() *Txn[V] {
+	return &Txn[V]{root: n, base: n}
+}
+
+// Commit closes the transaction and returns the new root. Watch channels of
+// nodes that the transaction replaced are closed.
+func (t *Txn[V]) Commit() *Node[V] {
+	root := t.commit()
+	notifyWatches(0, t.base, root)
+	return root
+}
+
+// commit closes the transaction and returns the new root, leaving the watch
+// channels to the caller.
+func (t *Txn[V]) commit() *Node[V] {
+	t.checkOpen()
+	t.close()
 	return t.root
 }
 
-func (t *Txn) Delete(k []byte) {
+// Abort closes the transaction, discarding its changes. Aborting a closed
+// transaction does nothing, so it is safe to defer.
+func (t *Txn[V]) Abort() {
+	if t.closed {
+		return
+	}
+	t.close()
+	t.root = t.base
+	t.changes = t.changes[:0]
+}
+
+// Savepoint returns the current state of the transaction, to be restored by
+// RollbackTo. Nodes created so far are no longer changed in place, so the
+// state stays intact whatever is written afterwards.
+func (t *Txn[V]) Savepoint() Savepoint[V] {
+	t.checkOpen()
+	t.mut = nil
+	t.nextID++
+	t.saves = append(t.saves, t.nextID)
+	return Savepoint[V]{txn: t, root: t.root, changes: len(t.changes), id: t.nextID}
+}
+
+// RollbackTo undoes every change made since sp was taken. The savepoint can
+// be rolled back to again, but any taken after it can not.
+func (t *Txn[V]) RollbackTo(sp Savepoint[V]) error {
+	t.checkOpen()
+	i := len(t.saves) - 1
+	for i >= 0 && t.saves[i] != sp.id {
+		i--
+	}
+	if sp.txn != t || i < 0 {
+		return ErrInvalidSavepoint
+	}
+	t.root = sp.root
+	t.mut = nil
+	t.saves = t.saves[:i+1]
+	if t.tracking() {
+		t.changes = t.changes[:sp.changes]
+	}
+	return nil
+}
+
+func (t *Txn[V]) Delete(k []byte) {
+	t.checkOpen()
+	if t.tracking() {
+		var zero V
+		old, ok := t.root.Lookup(k)
+		t.record(string(k), old, ok, zero, false)
+	}
 	if t.root != nil {
 		t.root = t.root.delete(t, 0, k)
 	}
 }
 
-func (t *Txn) DeleteString(k string) {
+func (t *Txn[V]) DeleteString(k string) {
+	t.checkOpen()
+	if t.tracking() {
+		var zero V
+		old, ok := t.root.LookupString(k)
+		t.record(k, old, ok, zero, false)
+	}
 	if t.root != nil {
 		t.root = t.root.deleteString(t, 0, k)
 	}
 }
 
-func (t *Txn) Merge(n *Node) {
+func (t *Txn[V]) Merge(n *Node[V]) {
+	t.checkOpen()
 	if n == nil {
 		return
 	}
+	if t.tracking() {
+		t.recordMerge(n)
+	}
 	if t.root == nil {
 		t.root = n
 		return
@@ -37,38 +156,172 @@ func (t *Txn) Merge(n *Node) {
 	t.root, _ = mergeNodes(t, 0, t.root, n, false)
 }
 
-func (t *Txn) Put(k []byte, v interface{}) {
+// MergeFunc is like Merge, but calls fn to pick the value for every key held
+// by both the transaction and n, rather than always taking the one from n.
+func (t *Txn[V]) MergeFunc(n *Node[V], fn Resolver[V]) {
+	t.checkOpen()
+	if n == nil {
+		return
+	}
+	if fn == nil || t.root == nil {
+		t.Merge(n)
+		return
+	}
+
+	// The outcome depends on fn, so rather than predicting the changes like
+	// Merge does, keep the old root intact and compare against it afterwards.
+	old := t.root
+	if t.tracking() {
+		t.mut = nil
+	}
+
+	t.resolve = fn
+	t.root, _ = mergeNodes(t, 0, t.root, n, false)
+	t.resolve = nil
+
+	// Keys the resolver deleted are left holding the zero value; remove them
+	// the same way Delete would.
+	for _, k := range t.dropped {
+		if t.root == nil {
+			break
+		}
+		t.root = t.root.delete(t, 0, k)
+	}
+	t.dropped = t.dropped[:0]
+
+	if t.tracking() {
+		diffNodes(old, t.root, t.eq, t.recordNodes)
+	}
+}
+
+func (t *Txn[V]) Put(k []byte, v V) {
+	t.checkOpen()
+	if t.tracking() {
+		old, ok := t.root.Lookup(k)
+		t.record(string(k), old, ok, v, true)
+	}
 	if t.root != nil {
 		t.root = t.root.put(t, 0, k, v, nil)
 		return
 	}
-	t.root = t.newNode(k, v, nil)
+	t.root = t.newNode(k, v, true, nil)
 }
 
-func (t *Txn) PutString(k string, v interface{}) {
+func (t *Txn[V]) PutString(k string, v V) {
+	t.checkOpen()
+	if t.tracking() {
+		old, ok := t.root.LookupString(k)
+		t.record(k, old, ok, v, true)
+	}
 	if t.root != nil {
 		t.root = t.root.putString(t, 0, k, v, nil)
 		return
 	}
-	t.root = t.newNode(Key(k), v, nil)
+	t.root = t.newNode(Key(k), v, true, nil)
+}
+
+// Get returns the value for k, including uncommitted changes.
+func (t *Txn[V]) Get(k []byte) V {
+	return t.root.Get(k)
+}
+
+// GetString is like Get, but takes a string key.
+func (t *Txn[V]) GetString(k string) V {
+	return t.root.GetString(k)
+}
+
+// Lookup returns the value for k and whether there is one, including
+// uncommitted changes.
+func (t *Txn[V]) Lookup(k []byte) (V, bool) {
+	return t.root.Lookup(k)
+}
+
+// LookupString is like Lookup, but takes a string key.
+func (t *Txn[V]) LookupString(k string) (V, bool) {
+	return t.root.LookupString(k)
 }
 
-func (t *Txn) isMutable(n *Node) bool {
+// Len returns the number of values, including uncommitted changes.
+func (t *Txn[V]) Len() int {
+	return t.root.Len()
+}
+
+// Walk calls fn for every value, including uncommitted changes, in key order.
+// The trie must not be changed by fn.
+func (t *Txn[V]) Walk(fn func(*Node[V]) bool) {
+	t.root.Walk(fn)
+}
+
+// Prefix returns the subtree holding every key that starts with p, including
+// uncommitted changes. Nodes created by the transaction are changed in place
+// by later writes, so the subtree is only stable until the next write; take a
+// Savepoint first to keep it.
+func (t *Txn[V]) Prefix(p []byte) *Node[V] {
+	return t.root.Prefix(p)
+}
+
+// PrefixString is like Prefix, but takes a string prefix.
+func (t *Txn[V]) PrefixString(p string) *Node[V] {
+	return t.root.PrefixString(p)
+}
+
+// Iterator returns an Iterator over the trie, including uncommitted changes.
+// Like Prefix, it is only valid until the next write.
+func (t *Txn[V]) Iterator() *Iterator[V] {
+	return t.root.Iterator()
+}
+
+func (t *Txn[V]) checkOpen() {
+	if t.closed {
+		panic(ErrTxnClosed)
+	}
+}
+
+func (t *Txn[V]) close() {
+	t.closed = true
+	t.mut = nil
+	t.saves = nil
+}
+
+func (t *Txn[V]) equal(a, b V) bool {
+	if t == nil {
+		return Comparator[V](nil).equal(a, b)
+	}
+	return t.eq.equal(a, b)
+}
+
+func (t *Txn[V]) resolving() bool {
+	return t != nil && t.resolve != nil
+}
+
+func (t *Txn[V]) isMutable(n *Node[V]) bool {
 	if t == nil || t.mut == nil {
 		return false
 	}
 	return t.mut[n]
 }
 
-func (t *Txn) newNode(k Key, v interface{}, es edges) (n *Node) {
-	n = &Node{
-		key:   k,
-		value: v,
-		edges: es,
+// reuse returns n after a write beneath it that kept its edges. If n is
+// mutable, a descendant may have changed in place, so its size is recounted.
+func (t *Txn[V]) reuse(n *Node[V]) *Node[V] {
+	if t.isMutable(n) {
+		n.resize()
+	}
+	return n
+}
+
+// newNode returns a node for k with the edges es, holding v if has is true.
+func (t *Txn[V]) newNode(k Key, v V, has bool, es edges[V]) (n *Node[V]) {
+	n = &Node[V]{
+		key:     k,
+		value:   v,
+		present: has,
+		edges:   es,
 	}
+	n.resize()
 	if t != nil {
 		if t.mut == nil {
-			t.mut = make(map[*Node]bool)
+			t.mut = make(map[*Node[V]]bool)
 		}
 		t.mut[n] = true
 	}
@@ -0,0 +1,148 @@
+package trie
+
+import "testing"
+
+func TestTxnAbort(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	_, ch := n.GetWatchString("abc")
+
+	tx := n.Txn()
+	tx.TrackChanges()
+	tx.PutString("abc", 40)
+	tx.Abort()
+	tx.Abort()
+
+	if tx.Changes() != nil {
+		t.Errorf("expected an aborted transaction to have no changes")
+	}
+	if isClosed(ch) {
+		t.Errorf("expected an aborted transaction to leave watches open")
+	}
+	if v := n.GetString("abc"); v != 4 {
+		t.Errorf(`expected "abc" to still be 4, got %d`, v)
+	}
+	expectClosed(t, "Put", func() { tx.PutString("abc", 1) })
+	expectClosed(t, "Commit", func() { tx.Commit() })
+}
+
+func TestTxnClosedAfterCommit(t *testing.T) {
+	tx := new(Txn[int])
+	tx.PutString("foo", 1)
+	n := tx.Commit()
+
+	expectClosed(t, "Put", func() { tx.PutString("bar", 2) })
+	expectClosed(t, "Delete", func() { tx.DeleteString("foo") })
+	expectClosed(t, "Merge", func() { tx.Merge(n) })
+	expectClosed(t, "Savepoint", func() { tx.Savepoint() })
+	expectClosed(t, "Commit", func() { tx.Commit() })
+	tx.Abort()
+
+	if v := n.GetString("foo"); v != 1 || n.Len() != 1 {
+		t.Errorf("expected the committed root to be unchanged, got %#v", n)
+	}
+}
+
+func TestTxnSavepoints(t *testing.T) {
+	tx := new(Txn[int])
+	tx.TrackChanges()
+	tx.PutString("foo", 1)
+	sp1 := tx.Savepoint()
+
+	tx.PutString("foo", 2) // would change the node in place without the savepoint
+	tx.PutString("foobar", 3)
+	sp2 := tx.Savepoint()
+	tx.DeleteString("foobar")
+
+	if err := tx.RollbackTo(sp2); err != nil {
+		t.Fatal(err)
+	}
+	if v := tx.root.GetString("foobar"); v != 3 {
+		t.Errorf(`expected "foobar" to be back to 3, got %d`, v)
+	}
+
+	if err := tx.RollbackTo(sp1); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.RollbackTo(sp2); err != ErrInvalidSavepoint {
+		t.Errorf("expected a later savepoint to be invalid, got %v", err)
+	}
+	if err := new(Txn[int]).RollbackTo(sp1); err != ErrInvalidSavepoint {
+		t.Errorf("expected a foreign savepoint to be invalid, got %v", err)
+	}
+
+	sp3 := tx.Savepoint()
+	if err := tx.RollbackTo(sp2); err != ErrInvalidSavepoint {
+		t.Errorf("expected an undone savepoint to stay invalid, got %v", err)
+	}
+	tx.PutString("fob", 4)
+	if err := tx.RollbackTo(sp3); err != nil {
+		t.Fatal(err)
+	}
+
+	n := tx.Commit()
+	if v := n.GetString("foo"); v != 1 || n.Len() != 1 {
+		t.Errorf(`expected only "foo" = 1, got %#v`, n)
+	}
+	if cs := tx.Changes(); len(cs) != 1 || cs[0].Kind != Inserted || cs[0].New != 1 {
+		t.Errorf("expected the rolled back changes to be forgotten, got %v", cs)
+	}
+}
+
+func expectClosed(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if err := recover(); err != ErrTxnClosed {
+			t.Errorf("expected %s to panic with ErrTxnClosed, got %v", name, err)
+		}
+	}()
+	fn()
+}
+
+func TestTxnReadYourWrites(t *testing.T) {
+	tx := buildIterTrie(iterKeys).Txn()
+	tx.PutString("abc", 40)
+	tx.DeleteString("ba")
+	tx.Put([]byte("bz"), 50)
+
+	if v := tx.GetString("abc"); v != 40 {
+		t.Errorf(`expected "abc" to be 40, got %d`, v)
+	}
+	if v := tx.Get([]byte("ba")); v != 0 {
+		t.Errorf(`expected "ba" to be deleted, got %d`, v)
+	}
+	if l := tx.Len(); l != len(iterKeys) {
+		t.Errorf("expected Len to be %d, got %d", len(iterKeys), l)
+	}
+
+	var keys []string
+	tx.PrefixString("b").Walk(func(n *Node[int]) bool {
+		keys = append(keys, string(n.Key()))
+		return true
+	})
+	if want := []string{"b", "bab", "bz"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+
+	it := tx.Iterator()
+	if !it.SeekGEString("bb") || string(it.Key()) != "bz" || it.Value() != 50 {
+		t.Errorf(`expected the iterator to find "bz", got %q`, it.Key())
+	}
+
+	var count int
+	tx.Walk(func(*Node[int]) bool {
+		count++
+		return true
+	})
+	if count != len(iterKeys) {
+		t.Errorf("expected Walk to visit %d values, got %d", len(iterKeys), count)
+	}
+
+	tx.PutString("bz", 51) // changes the node in place
+	if v := tx.GetString("bz"); v != 51 {
+		t.Errorf(`expected "bz" to be 51, got %d`, v)
+	}
+	if tx.Prefix([]byte("x")) != nil {
+		t.Errorf(`expected no keys under "x"`)
+	}
+}
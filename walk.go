@@ -1,29 +1,79 @@
 package trie
 
-func (n *Node) Key() []byte {
+func (n *Node[V]) Key() []byte {
 	return n.key
 }
 
-func (n *Node) Value() interface{} {
+func (n *Node[V]) Value() V {
 	return n.value
 }
 
-func (n *Node) Walk(fn func(*Node) bool) {
+func (n *Node[V]) Walk(fn func(*Node[V]) bool) {
 	if n == nil {
 		return
 	}
 	n.walk(fn)
 }
 
-func (n *Node) WalkChan(ch chan<- *Node) {
+// WalkPath calls fn for every value whose key is a prefix of k, from the
+// shortest to the longest, until fn returns false.
+func (n *Node[V]) WalkPath(k []byte, fn func(*Node[V]) bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToBytes(k[i:end]) {
+			return
+		}
+		if n.hasValue() && !fn(n) {
+			return
+		}
+		if len(k) == end {
+			return
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+}
+
+// WalkPathString is like WalkPath, but takes a string key.
+func (n *Node[V]) WalkPathString(k string, fn func(*Node[V]) bool) {
+	for i := 0; n != nil; {
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToString(k[i:end]) {
+			return
+		}
+		if n.hasValue() && !fn(n) {
+			return
+		}
+		if len(k) == end {
+			return
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+}
+
+// WalkReverse calls fn for every value in reverse key order, until fn returns
+// false.
+func (n *Node[V]) WalkReverse(fn func(*Node[V]) bool) {
+	if n == nil {
+		return
+	}
+	n.walkReverse(fn)
+}
+
+func (n *Node[V]) WalkChan(ch chan<- *Node[V]) {
 	if n != nil {
 		n.walkChan(ch)
 	}
 	close(ch)
 }
 
-func (n *Node) walk(fn func(*Node) bool) {
-	if n.value != nil && !fn(n) {
+func (n *Node[V]) walk(fn func(*Node[V]) bool) {
+	if n.hasValue() && !fn(n) {
 		return
 	}
 	for _, nd := range n.edges {
@@ -31,8 +81,17 @@ func (n *Node) walk(fn func(*Node) bool) {
 	}
 }
 
-func (n *Node) walkChan(ch chan<- *Node) {
-	if n.value != nil {
+func (n *Node[V]) walkReverse(fn func(*Node[V]) bool) bool {
+	for i := len(n.edges) - 1; i >= 0; i-- {
+		if !n.edges[i].walkReverse(fn) {
+			return false
+		}
+	}
+	return !n.hasValue() || fn(n)
+}
+
+func (n *Node[V]) walkChan(ch chan<- *Node[V]) {
+	if n.hasValue() {
 		ch <- n
 	}
 	for _, nd := range n.edges {
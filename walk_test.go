@@ -0,0 +1,65 @@
+package trie
+
+import "testing"
+
+func TestWalkPath(t *testing.T) {
+	n := buildIterTrie([]string{"/", "/a", "/a/b", "/a/b/c", "/a/bc", "/b"})
+
+	collect := func(k string, limit int) []string {
+		var a, b []string
+		n.WalkPath([]byte(k), func(nd *Node[int]) bool {
+			a = append(a, string(nd.Key()))
+			return len(a) < limit
+		})
+		n.WalkPathString(k, func(nd *Node[int]) bool {
+			b = append(b, string(nd.Key()))
+			return len(b) < limit
+		})
+		if !equalStrings(a, b) {
+			t.Errorf("WalkPath does not agree with WalkPathString for %q: %q != %q", k, a, b)
+		}
+		return a
+	}
+
+	table := []struct {
+		K     string
+		Limit int
+		Keys  []string
+	}{
+		{"/a/b/c/d", 10, []string{"/", "/a", "/a/b", "/a/b/c"}},
+		{"/a/b/c/d", 2, []string{"/", "/a"}},
+		{"/a/bc", 10, []string{"/", "/a", "/a/b", "/a/bc"}},
+		{"/a/", 10, []string{"/", "/a"}},
+		{"/c", 10, []string{"/"}},
+		{"", 10, nil},
+	}
+	for _, x := range table {
+		if got := collect(x.K, x.Limit); !equalStrings(got, x.Keys) {
+			t.Errorf("%q: expected %q, got %q", x.K, x.Keys, got)
+		}
+	}
+}
+
+func TestWalkReverse(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	var keys []string
+	n.WalkReverse(func(nd *Node[int]) bool {
+		keys = append(keys, string(nd.Key()))
+		return len(keys) < 5
+	})
+	if want := []string{"food", "foo baz", "foo bar", "foo", "c"}; !equalStrings(keys, want) {
+		t.Errorf("expected %q, got %q", want, keys)
+	}
+
+	keys = keys[:0]
+	n.WalkReverse(func(nd *Node[int]) bool {
+		keys = append(keys, string(nd.Key()))
+		return true
+	})
+	for i, k := range keys {
+		if want := iterKeys[len(iterKeys)-1-i]; k != want {
+			t.Fatalf("expected %q at %d, got %q", want, i, k)
+		}
+	}
+}
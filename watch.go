@@ -0,0 +1,183 @@
+package trie
+
+// closedWatch is the channel of a node that has been replaced. Once a node
+// holds it, the node is never watched again.
+var closedWatch = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// replacedAux is shared by the nodes replaced by a commit that had no nodeAux
+// of their own, so replacing them doesn't allocate. It is never written to;
+// getAux gives such a node its own copy first.
+var replacedAux = func() *nodeAux {
+	a := new(nodeAux)
+	a.watch.Store(&closedWatch)
+	return a
+}()
+
+// GetWatch returns the value for k and a channel that is closed once a
+// committed transaction changes that value. The channel belongs to the
+// closest node to k, so it may also be closed by changes to nearby keys.
+//
+// Watch channels are only closed by Txn.Commit, not by Node.Put and friends.
+func (n *Node[V]) GetWatch(k []byte) (V, <-chan struct{}) {
+	var (
+		value V
+		last  *Node[V]
+	)
+	for i := 0; n != nil; {
+		last = n
+		end := len(n.key)
+
+		if len(k) < end || !n.key[i:].EqualToBytes(k[i:end]) {
+			break
+		}
+		if len(k) == end {
+			value = n.value
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(k[i], i)
+	}
+	return value, last.watchChan()
+}
+
+// GetWatchString is like GetWatch, but takes a string key.
+func (n *Node[V]) GetWatchString(k string) (V, <-chan struct{}) {
+	return n.GetWatch([]byte(k))
+}
+
+// PrefixWatch is like Prefix, but also returns a channel that is closed once
+// a committed transaction changes any key starting with p.
+func (n *Node[V]) PrefixWatch(p []byte) (*Node[V], <-chan struct{}) {
+	var last *Node[V]
+	for i := 0; n != nil; {
+		last = n
+		end := len(n.key)
+
+		if len(p) <= end {
+			if n.key[i:len(p)].EqualToBytes(p[i:]) {
+				return n, n.watchChan()
+			}
+			break
+		}
+		if !n.key[i:].EqualToBytes(p[i:end]) {
+			break
+		}
+		i = end
+
+		_, n = n.edges.get(p[i], i)
+	}
+	return nil, last.watchChan()
+}
+
+// PrefixWatchString is like PrefixWatch, but takes a string prefix.
+func (n *Node[V]) PrefixWatchString(p string) (*Node[V], <-chan struct{}) {
+	return n.PrefixWatch([]byte(p))
+}
+
+// watchChan returns the node's channel, creating it if needed. A nil node
+// returns a channel that is never closed.
+func (n *Node[V]) watchChan() <-chan struct{} {
+	if n == nil {
+		return nil
+	}
+	if a := n.aux.Load(); a != nil {
+		if ch := a.watch.Load(); ch != nil {
+			return *ch
+		}
+	}
+	a := n.getAux()
+	for {
+		if ch := a.watch.Load(); ch != nil {
+			return *ch
+		}
+		ch := make(chan struct{})
+		if a.watch.CompareAndSwap(nil, &ch) {
+			return ch
+		}
+	}
+}
+
+// replaced closes the channel of a node that is no longer in the trie.
+func (n *Node[V]) replaced() {
+	if n.aux.CompareAndSwap(nil, replacedAux) {
+		return
+	}
+	a := n.aux.Load()
+	if a == replacedAux {
+		return
+	}
+	if ch := a.watch.Swap(&closedWatch); ch != nil && ch != &closedWatch {
+		close(*ch)
+	}
+}
+
+// touched closes the channel of a node that is still in the trie, but whose
+// surroundings changed. The node gets a new channel the next time one is
+// asked for.
+func (n *Node[V]) touched() {
+	a := n.aux.Load()
+	if a == nil {
+		return
+	}
+	if ch := a.watch.Load(); ch == nil || ch == &closedWatch {
+		return
+	}
+	if ch := a.watch.Swap(nil); ch != nil && ch != &closedWatch {
+		close(*ch)
+	}
+}
+
+// notifyWatches closes the channels of the nodes of old that are not part of
+// new. Nodes are placed by their keys, so a node missing from its place in new
+// is gone; like Diff, shared subtrees are skipped.
+func notifyWatches[V any](depth int, a, b *Node[V]) {
+	switch {
+	case a == b, a == nil:
+		return
+	case b == nil:
+		a.walkNodes(func(n *Node[V]) { n.replaced() })
+		return
+	}
+
+	n, short := a.key.commonBytesLen(b.key, depth)
+	switch {
+	case !short && n == len(b.key): // same key
+		a.replaced()
+		notifyEdges(n, a.edges, b.edges)
+	case !short: // a is a prefix of b
+		a.replaced()
+		notifyEdges(n, a.edges, edges[V]{b})
+	case n == len(b.key): // b was inserted above a
+		a.touched()
+		notifyEdges(n, edges[V]{a}, b.edges)
+	default:
+		a.walkNodes(func(n *Node[V]) { n.replaced() })
+	}
+}
+
+func notifyEdges[V any](depth int, a, b edges[V]) {
+	for len(a) > 0 {
+		for len(b) > 0 && b[0].key[depth] < a[0].key[depth] {
+			b = b[1:]
+		}
+		if len(b) > 0 && b[0].key[depth] == a[0].key[depth] {
+			notifyWatches(depth+1, a[0], b[0])
+		} else {
+			notifyWatches(depth+1, a[0], nil)
+		}
+		a = a[1:]
+	}
+}
+
+// walkNodes calls fn for every node, with or without a value.
+func (n *Node[V]) walkNodes(fn func(*Node[V])) {
+	fn(n)
+	for _, nd := range n.edges {
+		nd.walkNodes(fn)
+	}
+}
@@ -0,0 +1,125 @@
+package trie
+
+import "testing"
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestGetWatch(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	v, abc := n.GetWatchString("abc")
+	if v != 4 {
+		t.Errorf(`expected "abc" to be 4, got %d`, v)
+	}
+	_, abd := n.GetWatchString("abd")
+	_, bz := n.GetWatchString("bz")
+	_, food := n.GetWatchString("food")
+
+	tx := n.Txn()
+	tx.PutString("abc", 40)
+	tx.PutString("bz", 1)
+	m := tx.Commit()
+
+	if !isClosed(abc) {
+		t.Errorf(`expected the watch on "abc" to be closed`)
+	}
+	if !isClosed(bz) {
+		t.Errorf(`expected the watch on missing "bz" to be closed`)
+	}
+	if isClosed(abd) || isClosed(food) {
+		t.Errorf("expected the watches on unchanged keys to stay open")
+	}
+
+	_, abc = m.GetWatchString("abc")
+	if isClosed(abc) {
+		t.Errorf("expected a fresh watch on the new root to be open")
+	}
+	m.Txn().Commit()
+	if isClosed(abc) || isClosed(abd) {
+		t.Errorf("expected an empty commit to leave watches open")
+	}
+}
+
+func TestGetWatchSplit(t *testing.T) {
+	n := (*Node[int])(nil).PutString("foobar", 1)
+
+	_, ch := n.GetWatchString("fob")
+	tx := n.Txn()
+	tx.PutString("fob", 2)
+	m := tx.Commit()
+
+	if !isClosed(ch) {
+		t.Errorf("expected inserting above the root to close its watch")
+	}
+	if m.edges[1] != n {
+		t.Fatalf("expected the old root to be reused")
+	}
+	if _, ch = m.GetWatchString("foobar"); isClosed(ch) {
+		t.Errorf("expected the reused node to hand out a new watch")
+	}
+}
+
+func TestPrefixWatch(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+
+	p, foo := n.PrefixWatchString("foo ")
+	if p.Len() != 2 {
+		t.Errorf(`expected "foo " to hold 2 values, got %d`, p.Len())
+	}
+	_, a := n.PrefixWatchString("a")
+	_, x := n.PrefixWatchString("x")
+
+	tx := n.Txn()
+	tx.DeleteString("foo baz")
+	tx.Commit()
+
+	if !isClosed(foo) {
+		t.Errorf(`expected the watch on "foo " to be closed`)
+	}
+	if isClosed(a) {
+		t.Errorf(`expected the watch on "a" to stay open`)
+	}
+	if !isClosed(x) {
+		t.Errorf("expected the watch on the root to be closed")
+	}
+}
+
+func TestWatchReplacedUnwatched(t *testing.T) {
+	n := buildIterTrie(iterKeys)
+	tx := n.Txn()
+	tx.PutString(iterKeys[0], 99)
+	tx.Commit()
+
+	// Nodes nobody watched are replaced without allocating their own state.
+	var replaced int
+	n.walkNodes(func(nd *Node[int]) {
+		switch nd.aux.Load() {
+		case nil:
+		case replacedAux:
+			replaced++
+		default:
+			t.Errorf("expected no state for %q", nd.key)
+		}
+	})
+	if replaced == 0 {
+		t.Errorf("expected some nodes to be marked replaced")
+	}
+
+	// They still report the change to anyone watching the old trie.
+	if _, ch := n.GetWatchString(iterKeys[0]); !isClosed(ch) {
+		t.Errorf("expected the old trie's watch to be closed")
+	}
+	if _, err := n.Hash(); err != nil || n.aux.Load() == replacedAux {
+		t.Errorf("expected hashing to give a replaced node its own state, %v", err)
+	}
+	if _, ch := n.GetWatchString(iterKeys[0]); !isClosed(ch) {
+		t.Errorf("expected the watch to stay closed after hashing")
+	}
+}